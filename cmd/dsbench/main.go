@@ -0,0 +1,256 @@
+// Command dsbench generates load against a master, or a single worker, and
+// reports throughput, latency percentiles and errors.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+)
+
+// options holds the command-line flags.
+type options struct {
+	target      string
+	master      string
+	worker      string
+	tls         config.TLSConfig
+	concurrency int
+	rate        float64
+	duration    time.Duration
+	requests    int
+	mix         string
+	sizes       string
+	timeout     time.Duration
+	output      string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.target, "target", "master", "What to drive: master (POST /tasks) or worker (ProcessTask over gRPC)")
+	flag.StringVar(&opts.master, "master", "localhost:8080", "Master HTTP address")
+	flag.StringVar(&opts.worker, "worker", "localhost:50051", "Worker gRPC address, with -target worker")
+	flag.StringVar(&opts.tls.CertFile, "tls-cert", "", "Client certificate for a worker that requires one")
+	flag.StringVar(&opts.tls.KeyFile, "tls-key", "", "Client key for a worker that requires one")
+	flag.StringVar(&opts.tls.CAFile, "tls-ca", "", "CA certificate to verify the worker with; enables TLS")
+	flag.IntVar(&opts.concurrency, "concurrency", 10, "Requests in flight at once")
+	flag.Float64Var(&opts.rate, "rate", 0, "Requests per second to start (0 sends as fast as concurrency allows)")
+	flag.DurationVar(&opts.duration, "duration", 10*time.Second, "How long to generate load")
+	flag.IntVar(&opts.requests, "requests", 0, "Stop after this many requests (0 for no limit)")
+	flag.StringVar(&opts.mix, "mix", "compute", "Task types to send with optional weights, e.g. compute:3,process:1")
+	flag.StringVar(&opts.sizes, "payload-sizes", "64", "Comma-separated payload sizes in bytes, picked at random")
+	flag.DurationVar(&opts.timeout, "timeout", 30*time.Second, "Timeout for each request")
+	flag.StringVar(&opts.output, "o", "text", "Report format (text, json)")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "dsbench: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	if opts.concurrency < 1 {
+		return fmt.Errorf("-concurrency must be at least 1")
+	}
+	if opts.output != "text" && opts.output != "json" {
+		return fmt.Errorf("unknown report format %q (want text or json)", opts.output)
+	}
+
+	taskTypes, err := parseMix(opts.mix)
+	if err != nil {
+		return err
+	}
+	payloads, err := parsePayloadSizes(opts.sizes)
+	if err != nil {
+		return err
+	}
+
+	var t target
+	var targetDesc string
+	switch opts.target {
+	case "master":
+		t = newMasterTarget(opts.master, opts.concurrency)
+		targetDesc = "master " + opts.master
+	case "worker":
+		if t, err = newWorkerTarget(opts.worker, opts.tls); err != nil {
+			return err
+		}
+		targetDesc = "worker " + opts.worker
+	default:
+		return fmt.Errorf("unknown target %q (want master or worker)", opts.target)
+	}
+	defer t.Close()
+
+	// Stopping only ends the generation of new requests; those in flight
+	// still finish and are counted
+	ctx, cancel := context.WithTimeout(context.Background(), opts.duration)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rec := newRecorder()
+	jobs := make(chan job)
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				reqCtx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+				start := time.Now()
+				err := t.send(reqCtx, j.taskType, j.payload)
+				rec.add(j.taskType, time.Since(start), err)
+				cancel()
+			}
+		}()
+	}
+
+	fmt.Fprintf(os.Stderr, "Benchmarking %s for %s...\n", targetDesc, opts.duration)
+	start := time.Now()
+	generate(ctx, jobs, rec, opts.rate, opts.requests, func() job {
+		return job{taskType: taskTypes.pick(), payload: payloads[rand.Intn(len(payloads))]}
+	})
+	close(jobs)
+	wg.Wait()
+
+	report := rec.report(targetDesc, opts.concurrency, opts.rate, time.Since(start))
+	if opts.output == "json" {
+		return report.writeJSON(os.Stdout)
+	}
+	return report.writeText(os.Stdout)
+}
+
+type job struct {
+	taskType string
+	payload  string
+}
+
+// generate hands out jobs until ctx is done or the request limit is reached.
+// Without a rate, a job is handed out as soon as a sender is free. With one,
+// jobs are started on a fixed schedule, and a job that finds every sender
+// busy is skipped so that a slow target does not lower the offered load
+// unnoticed.
+func generate(ctx context.Context, jobs chan<- job, rec *recorder, rate float64, requests int, next func() job) {
+	var tick <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for sent := 0; requests == 0 || sent < requests; sent++ {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- next():
+			default:
+				rec.skip()
+			}
+			continue
+		}
+
+		select {
+		case jobs <- next():
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type weightedType struct {
+	taskType string
+	weight   int
+}
+
+type taskMix struct {
+	types []weightedType
+	total int
+}
+
+// pick returns a task type at random, in proportion to its weight.
+func (m *taskMix) pick() string {
+	n := rand.Intn(m.total)
+	for _, t := range m.types {
+		if n < t.weight {
+			return t.taskType
+		}
+		n -= t.weight
+	}
+	return m.types[len(m.types)-1].taskType
+}
+
+// parseMix parses "type[:weight],..."; the weight defaults to 1.
+func parseMix(spec string) (*taskMix, error) {
+	mix := &taskMix{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		taskType, weightStr, hasWeight := strings.Cut(item, ":")
+		weight := 1
+		if hasWeight {
+			var err error
+			weight, err = strconv.Atoi(weightStr)
+			if err != nil || weight < 1 {
+				return nil, fmt.Errorf("invalid weight in -mix %q: want a positive integer", item)
+			}
+		}
+		mix.types = append(mix.types, weightedType{taskType: taskType, weight: weight})
+		mix.total += weight
+	}
+
+	if len(mix.types) == 0 {
+		return nil, fmt.Errorf("-mix needs at least one task type")
+	}
+	return mix, nil
+}
+
+// parsePayloadSizes builds one payload of each listed size.
+func parsePayloadSizes(spec string) ([]string, error) {
+	var payloads []string
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		size, err := strconv.Atoi(item)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid payload size %q: want a positive number of bytes", item)
+		}
+		payloads = append(payloads, randomPayload(size))
+	}
+
+	if len(payloads) == 0 {
+		return nil, fmt.Errorf("-payload-sizes needs at least one size")
+	}
+	return payloads, nil
+}
+
+func randomPayload(size int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = letters[rand.Intn(len(letters))]
+	}
+	return string(b)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// recorder collects the outcome of every request.
+type recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	types     map[string]*typeStats
+	errors    map[string]*ErrorReport
+	skipped   int
+}
+
+type typeStats struct {
+	latencies []time.Duration
+	failed    int
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		types:  make(map[string]*typeStats),
+		errors: make(map[string]*ErrorReport),
+	}
+}
+
+func (r *recorder) add(taskType string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latencies = append(r.latencies, latency)
+
+	stats, ok := r.types[taskType]
+	if !ok {
+		stats = &typeStats{}
+		r.types[taskType] = stats
+	}
+	stats.latencies = append(stats.latencies, latency)
+
+	if err == nil {
+		return
+	}
+	stats.failed++
+
+	kind, msg := "error", err.Error()
+	var benchErr *benchError
+	if errors.As(err, &benchErr) {
+		kind, msg = benchErr.kind, benchErr.msg
+	}
+	report, ok := r.errors[kind]
+	if !ok {
+		report = &ErrorReport{Kind: kind, Example: msg}
+		r.errors[kind] = report
+	}
+	report.Count++
+}
+
+// skip records a request the rate called for while every connection was
+// still busy with an earlier one.
+func (r *recorder) skip() {
+	r.mu.Lock()
+	r.skipped++
+	r.mu.Unlock()
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Target      string  `json:"target"`
+	Concurrency int     `json:"concurrency"`
+	Rate        float64 `json:"rate,omitempty"`
+	Elapsed     float64 `json:"elapsed_seconds"`
+
+	Requests   int     `json:"requests"`
+	Succeeded  int     `json:"succeeded"`
+	Failed     int     `json:"failed"`
+	Skipped    int     `json:"skipped,omitempty"`
+	Throughput float64 `json:"throughput_per_second"`
+
+	Latency LatencyReport          `json:"latency_ms"`
+	Types   map[string]*TypeReport `json:"task_types"`
+	Errors  []*ErrorReport         `json:"errors,omitempty"`
+}
+
+type LatencyReport struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+type TypeReport struct {
+	Requests int           `json:"requests"`
+	Failed   int           `json:"failed"`
+	Latency  LatencyReport `json:"latency_ms"`
+}
+
+type ErrorReport struct {
+	Kind    string `json:"kind"`
+	Count   int    `json:"count"`
+	Example string `json:"example"`
+}
+
+func (r *recorder) report(target string, concurrency int, rate float64, elapsed time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{
+		Target:      target,
+		Concurrency: concurrency,
+		Rate:        rate,
+		Elapsed:     elapsed.Seconds(),
+		Requests:    len(r.latencies),
+		Skipped:     r.skipped,
+		Latency:     latencyReport(r.latencies),
+		Types:       make(map[string]*TypeReport),
+	}
+
+	for taskType, stats := range r.types {
+		report.Failed += stats.failed
+		report.Types[taskType] = &TypeReport{
+			Requests: len(stats.latencies),
+			Failed:   stats.failed,
+			Latency:  latencyReport(stats.latencies),
+		}
+	}
+	report.Succeeded = report.Requests - report.Failed
+	if elapsed > 0 {
+		report.Throughput = float64(report.Succeeded) / elapsed.Seconds()
+	}
+
+	for _, errReport := range r.errors {
+		report.Errors = append(report.Errors, errReport)
+	}
+	sort.Slice(report.Errors, func(i, j int) bool {
+		if report.Errors[i].Count != report.Errors[j].Count {
+			return report.Errors[i].Count > report.Errors[j].Count
+		}
+		return report.Errors[i].Kind < report.Errors[j].Kind
+	})
+
+	return report
+}
+
+func latencyReport(latencies []time.Duration) LatencyReport {
+	if len(latencies) == 0 {
+		return LatencyReport{}
+	}
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+
+	return LatencyReport{
+		Min:  ms(sorted[0]),
+		Mean: ms(total / time.Duration(len(sorted))),
+		P50:  ms(percentile(sorted, 50)),
+		P90:  ms(percentile(sorted, 90)),
+		P99:  ms(percentile(sorted, 99)),
+		Max:  ms(sorted[len(sorted)-1]),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func (r *Report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	fmt.Fprintf(w, "Target:      %s\n", r.Target)
+	if r.Rate > 0 {
+		fmt.Fprintf(w, "Load:        %.1f req/s, concurrency %d\n", r.Rate, r.Concurrency)
+	} else {
+		fmt.Fprintf(w, "Load:        concurrency %d\n", r.Concurrency)
+	}
+	fmt.Fprintf(w, "Elapsed:     %.2fs\n", r.Elapsed)
+	fmt.Fprintf(w, "Requests:    %d (%d succeeded, %d failed", r.Requests, r.Succeeded, r.Failed)
+	if r.Skipped > 0 {
+		fmt.Fprintf(w, ", %d skipped", r.Skipped)
+	}
+	fmt.Fprintln(w, ")")
+	fmt.Fprintf(w, "Throughput:  %.2f tasks/s\n\n", r.Throughput)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "TASK TYPE\tREQUESTS\tFAILED\tMIN\tMEAN\tP50\tP90\tP99\tMAX\t")
+
+	types := make([]string, 0, len(r.Types))
+	for taskType := range r.Types {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+
+	for _, taskType := range types {
+		stats := r.Types[taskType]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t\n", taskType, stats.Requests, stats.Failed, latencyColumns(stats.Latency))
+	}
+	fmt.Fprintf(tw, "all\t%d\t%d\t%s\t\n", r.Requests, r.Failed, latencyColumns(r.Latency))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		for _, errReport := range r.Errors {
+			fmt.Fprintf(w, "  %6d  %s (e.g. %s)\n", errReport.Count, errReport.Kind, errReport.Example)
+		}
+	}
+	return nil
+}
+
+func latencyColumns(l LatencyReport) string {
+	return fmt.Sprintf("%.1fms\t%.1fms\t%.1fms\t%.1fms\t%.1fms\t%.1fms", l.Min, l.Mean, l.P50, l.P90, l.P99, l.Max)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// target runs one task against the system under test.
+type target interface {
+	send(ctx context.Context, taskType, payload string) error
+	Close() error
+}
+
+// benchError is a failed request. Kind groups failures in the report, so it
+// must not contain per-request details; those go in msg.
+type benchError struct {
+	kind string
+	msg  string
+}
+
+func (e *benchError) Error() string {
+	return e.kind + ": " + e.msg
+}
+
+// masterTarget submits tasks with POST /tasks and waits for each to finish.
+type masterTarget struct {
+	url    string
+	client *http.Client
+}
+
+func newMasterTarget(addr string, concurrency int) *masterTarget {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	return &masterTarget{
+		url: strings.TrimRight(addr, "/") + "/v1/tasks",
+		client: &http.Client{
+			Transport: &http.Transport{MaxIdleConnsPerHost: concurrency},
+		},
+	}
+}
+
+func (t *masterTarget) send(ctx context.Context, taskType, payload string) error {
+	body, err := json.Marshal(map[string]string{"task_type": taskType, "payload": payload})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return &benchError{kind: "transport error", msg: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &benchError{kind: "transport error", msg: err.Error()}
+	}
+
+	// Failed tasks come back with their error code, and error responses
+	// with the code of the error
+	var taskResp struct {
+		TaskID    string `json:"task_id"`
+		Success   bool   `json:"success"`
+		Error     string `json:"error"`
+		ErrorCode string `json:"error_code"`
+		Code      string `json:"code"`
+	}
+	json.Unmarshal(data, &taskResp)
+
+	switch {
+	case taskResp.Success:
+		return nil
+	case taskResp.TaskID != "":
+		// The master created the task, but it did not succeed
+		return &benchError{kind: "task failed: " + taskResp.ErrorCode, msg: taskResp.Error}
+	default:
+		return &benchError{kind: fmt.Sprintf("HTTP %d %s", resp.StatusCode, taskResp.Code), msg: taskResp.Error}
+	}
+}
+
+func (t *masterTarget) Close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// workerTarget calls ProcessTask on a single worker, bypassing the master.
+type workerTarget struct {
+	conn   *grpc.ClientConn
+	client pb.WorkerServiceClient
+}
+
+func newWorkerTarget(addr string, tlsConfig config.TLSConfig) (*workerTarget, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig.Enabled() {
+		clientTLS, err := tlsConfig.ClientTLS()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(clientTLS)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	return &workerTarget{
+		conn:   conn,
+		client: pb.NewWorkerServiceClient(conn),
+	}, nil
+}
+
+func (t *workerTarget) send(ctx context.Context, taskType, payload string) error {
+	resp, err := t.client.ProcessTask(ctx, &pb.TaskRequest{
+		TaskId:      uuid.New().String(),
+		TaskType:    taskType,
+		Payload:     []byte(payload),
+		ContentType: "text/plain; charset=utf-8",
+	})
+	if err != nil {
+		st := status.Convert(err)
+		return &benchError{kind: "gRPC " + st.Code().String(), msg: st.Message()}
+	}
+	if !resp.Success {
+		return &benchError{kind: "task failed", msg: resp.Error}
+	}
+	return nil
+}
+
+func (t *workerTarget) Close() error {
+	return t.conn.Close()
+}
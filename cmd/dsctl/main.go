@@ -0,0 +1,542 @@
+// Command dsctl talks to a master from the command line.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pkg/client"
+)
+
+const usage = `Usage: dsctl <command> [flags] [args]
+
+Commands:
+  submit              Submit a task
+  get <task-id>       Show a task
+  result <task-id>    Write a completed task's result to stdout
+  wait <task-id>      Wait for a task to finish
+  cancel <task-id>    Cancel a scheduled or running task
+  workers list        List the workers in the pool
+  workers drain <id>  Cordon a worker and wait for its tasks to finish
+  task-types          List the task types declared on the master
+  blob put <file>     Store a file, or stdin with -, as a blob on the master
+  tail [task-id...]   Follow task events
+
+Every command accepts -master (default $DS_MASTER or localhost:8080) and
+-o table|json. Run "dsctl <command> -h" for its flags.
+`
+
+// errTaskFailed makes dsctl exit non-zero once a task it printed has failed
+var errTaskFailed = errors.New("task did not complete")
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "submit":
+		err = runSubmit(ctx, args)
+	case "get":
+		err = runGet(ctx, args)
+	case "result":
+		err = runResult(ctx, args)
+	case "wait":
+		err = runWait(ctx, args)
+	case "cancel":
+		err = runCancel(ctx, args)
+	case "workers":
+		err = runWorkers(ctx, args)
+	case "tail":
+		err = runTail(ctx, args)
+	case "task-types":
+		err = runTaskTypes(ctx, args)
+	case "blob":
+		err = runBlob(ctx, args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "dsctl: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.Is(err, errTaskFailed):
+		os.Exit(1)
+	default:
+		fmt.Fprintf(os.Stderr, "dsctl: %v\n", err)
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) {
+			for _, field := range apiErr.Fields {
+				fmt.Fprintf(os.Stderr, "  %s: %s\n", field.Field, field.Message)
+			}
+		}
+		os.Exit(1)
+	}
+}
+
+// options holds the flags shared by every command.
+type options struct {
+	master string
+	output string
+}
+
+func newFlagSet(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet("dsctl "+name, flag.ContinueOnError)
+
+	master := os.Getenv("DS_MASTER")
+	if master == "" {
+		master = "localhost:8080"
+	}
+	fs.StringVar(&opts.master, "master", master, "Master HTTP address")
+	fs.StringVar(&opts.output, "o", "table", "Output format (table, json)")
+	return fs
+}
+
+// anyArgs lets a command take any number of positional arguments
+const anyArgs = -1
+
+// parse parses the command's flags and checks that it got nargs positional
+// arguments. Flags may come before or after the arguments.
+func parse(fs *flag.FlagSet, opts *options, args []string, nargs int) (*client.Client, *printer, []string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, nil, nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if nargs != anyArgs && len(positional) != nargs {
+		return nil, nil, nil, fmt.Errorf("%s takes %d argument(s), got %d", fs.Name(), nargs, len(positional))
+	}
+
+	p, err := newPrinter(os.Stdout, opts.output)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	c, err := client.New(opts.master)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return c, p, positional, nil
+}
+
+func runSubmit(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("submit", &opts)
+	taskType := fs.String("type", "", "Task type (required)")
+	payload := fs.String("payload", "", "Task payload")
+	file := fs.String("file", "", "Read the payload from a file, or stdin with -")
+	contentType := fs.String("content-type", "", "Content type of the payload")
+	metadata := fs.String("metadata", "", "Comma-separated key=value metadata sent with the task")
+	delay := fs.Duration("delay", 0, "Run the task after this delay")
+	timeout := fs.Duration("timeout", 0, "Give up on the task when it runs longer (default: the task type's timeout)")
+	runAt := fs.String("run-at", "", "Run the task at this time (RFC3339)")
+	selector := fs.String("selector", "", "Only run the task on workers matching this label selector")
+	routingKey := fs.String("routing-key", "", "Send tasks sharing this key to the same worker")
+	idempotencyKey := fs.String("idempotency-key", "", "Return the earlier task when this key was used before")
+	wait := fs.Bool("wait", false, "Wait for the task to finish")
+
+	c, p, _, err := parse(fs, &opts, args, 0)
+	if err != nil {
+		return err
+	}
+	if *taskType == "" {
+		return errors.New("-type is required")
+	}
+
+	req := client.TaskRequest{
+		TaskType:       *taskType,
+		Delay:          *delay,
+		Timeout:        *timeout,
+		Selector:       *selector,
+		RoutingKey:     *routingKey,
+		IdempotencyKey: *idempotencyKey,
+		ContentType:    *contentType,
+	}
+
+	data, err := readPayload(*payload, *file)
+	if err != nil {
+		return err
+	}
+	// Text travels as is; anything else is sent as binary
+	if utf8.Valid(data) {
+		req.Payload = string(data)
+	} else {
+		req.Data = data
+	}
+
+	if req.Metadata, err = parseMetadata(*metadata); err != nil {
+		return err
+	}
+
+	if *runAt != "" {
+		req.RunAt, err = time.Parse(time.RFC3339, *runAt)
+		if err != nil {
+			return fmt.Errorf("invalid -run-at: %w", err)
+		}
+	}
+
+	task, err := c.Submit(ctx, req)
+	if err != nil {
+		return err
+	}
+	if *wait {
+		return waitFor(ctx, c, p, task.ID)
+	}
+	// Submit only returns the task's ID and state
+	task, err = c.Get(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	return p.task(task)
+}
+
+// readPayload returns the payload given inline, read from a file or, when
+// stdin is piped and neither is given, read from stdin.
+func readPayload(payload, file string) ([]byte, error) {
+	switch {
+	case payload != "" && file != "":
+		return nil, errors.New("-payload and -file are mutually exclusive")
+	case payload != "":
+		return []byte(payload), nil
+	case file == "-":
+		return readAll(os.Stdin)
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+		return data, nil
+	}
+
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice == 0 {
+		return readAll(os.Stdin)
+	}
+	return nil, errors.New("a payload is required: use -payload, -file or pipe it to stdin")
+}
+
+func readAll(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload: %w", err)
+	}
+	return data, nil
+}
+
+// parseMetadata parses "key=value,..." pairs.
+func parseMetadata(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("metadata %q must be in key=value form", pair)
+		}
+		metadata[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return metadata, nil
+}
+
+func runGet(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("get", &opts)
+
+	c, p, args, err := parse(fs, &opts, args, 1)
+	if err != nil {
+		return err
+	}
+
+	task, err := c.Get(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return p.task(task)
+}
+
+// runResult writes the raw result, which may be binary, rather than a table.
+func runResult(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("result", &opts)
+	out := fs.String("out", "", "Write the result to this file instead of stdout")
+
+	c, _, args, err := parse(fs, &opts, args, 1)
+	if err != nil {
+		return err
+	}
+
+	task, err := c.Get(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if task.State != client.TaskStateCompleted {
+		return fmt.Errorf("task is %s and has no result", task.State)
+	}
+
+	if *out != "" {
+		return os.WriteFile(*out, task.ResultData(), 0o644)
+	}
+	_, err = os.Stdout.Write(task.ResultData())
+	return err
+}
+
+func runWait(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("wait", &opts)
+	timeout := fs.Duration("timeout", 0, "Give up after this long (0 waits forever)")
+
+	c, p, args, err := parse(fs, &opts, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	return waitFor(ctx, c, p, args[0])
+}
+
+// waitFor waits for a task, prints it and reports whether it completed.
+func waitFor(ctx context.Context, c *client.Client, p *printer, taskID string) error {
+	task, err := c.Wait(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if err := p.task(task); err != nil {
+		return err
+	}
+	if task.State != client.TaskStateCompleted {
+		return errTaskFailed
+	}
+	return nil
+}
+
+func runCancel(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("cancel", &opts)
+
+	c, p, args, err := parse(fs, &opts, args, 1)
+	if err != nil {
+		return err
+	}
+
+	task, err := c.Cancel(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return p.task(task)
+}
+
+func runTaskTypes(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("task-types", &opts)
+
+	c, p, _, err := parse(fs, &opts, args, 0)
+	if err != nil {
+		return err
+	}
+
+	taskTypes, err := c.TaskTypes(ctx)
+	if err != nil {
+		return err
+	}
+	return p.taskTypes(taskTypes)
+}
+
+func runBlob(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "put" {
+		return errors.New("blob needs a subcommand: put")
+	}
+
+	var opts options
+	fs := newFlagSet("blob put", &opts)
+
+	c, p, positional, err := parse(fs, &opts, args[1:], 1)
+	if err != nil {
+		return err
+	}
+
+	data, err := readPayload("", positional[0])
+	if err != nil {
+		return err
+	}
+
+	blob, err := c.PutBlob(ctx, data)
+	if err != nil {
+		return err
+	}
+	return p.blob(blob)
+}
+
+func runWorkers(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("workers needs a subcommand: list or drain")
+	}
+
+	switch args[0] {
+	case "list":
+		return runWorkersList(ctx, args[1:])
+	case "drain":
+		return runWorkersDrain(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown workers subcommand %q", args[0])
+	}
+}
+
+func runWorkersList(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("workers list", &opts)
+
+	c, p, _, err := parse(fs, &opts, args, 0)
+	if err != nil {
+		return err
+	}
+
+	workers, err := c.ListWorkers(ctx)
+	if err != nil {
+		return err
+	}
+	return p.workers(workers)
+}
+
+// runWorkersDrain cordons a worker so it gets no new tasks, then waits until
+// the ones it is running have finished.
+func runWorkersDrain(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("workers drain", &opts)
+	timeout := fs.Duration("timeout", 5*time.Minute, "Give up after this long (0 waits forever)")
+	interval := fs.Duration("interval", time.Second, "How often to check the worker's active tasks")
+
+	c, p, args, err := parse(fs, &opts, args, 1)
+	if err != nil {
+		return err
+	}
+	workerID := args[0]
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	worker, err := c.Cordon(ctx, workerID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Cordoned %s, waiting for its tasks to finish\n", workerID)
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := c.WorkerStatus(ctx, workerID)
+		if err != nil {
+			return err
+		}
+		if status.ActiveTasks == 0 {
+			break
+		}
+		fmt.Fprintf(os.Stderr, "%s has %d active task(s)\n", workerID, status.ActiveTasks)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("%s still has %d active task(s): %w", workerID, status.ActiveTasks, ctx.Err())
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%s is drained\n", workerID)
+	return p.workers([]client.Worker{*worker})
+}
+
+// runTail prints task events as they happen. Given task IDs, it only follows
+// those and returns once they have all finished.
+func runTail(ctx context.Context, args []string) error {
+	var opts options
+	fs := newFlagSet("tail", &opts)
+
+	c, p, taskIDs, err := parse(fs, &opts, args, anyArgs)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before looking the tasks up, so none can finish in between
+	stream, err := c.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	pending := make(map[string]bool)
+	for _, taskID := range taskIDs {
+		task, err := c.Get(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		if err := p.event(task); err != nil {
+			return err
+		}
+		if !task.State.Finished() {
+			pending[taskID] = true
+		}
+	}
+	if len(taskIDs) > 0 && len(pending) == 0 {
+		return nil
+	}
+
+	errDone := errors.New("done")
+	err = stream.Follow(func(task *client.Task) error {
+		if len(taskIDs) > 0 && !pending[task.ID] {
+			return nil
+		}
+		if err := p.event(task); err != nil {
+			return err
+		}
+
+		if task.State.Finished() {
+			delete(pending, task.ID)
+			if len(taskIDs) > 0 && len(pending) == 0 {
+				return errDone
+			}
+		}
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, errDone):
+		return nil
+	case errors.Is(err, context.Canceled):
+		// Interrupted by the user
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pkg/client"
+)
+
+// printer writes results as a table or as JSON.
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case "table":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{w: w, json: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want table or json)", format)
+	}
+}
+
+func (p *printer) task(task *client.Task) error {
+	if p.json {
+		return p.writeJSON(task, "  ")
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TASK ID\tTYPE\tSTATE\tRESULT")
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", task.ID, task.TaskType, task.State, outcome(task))
+	return tw.Flush()
+}
+
+func (p *printer) workers(workers []client.Worker) error {
+	if p.json {
+		return p.writeJSON(workers, "  ")
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tURL\tCORDONED\tTASK TYPES\tLABELS")
+	for _, worker := range workers {
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%s\t%s\n",
+			worker.ID, worker.URL, worker.Cordoned, orNone(strings.Join(worker.TaskTypes, ",")), orNone(formatLabels(worker.Labels)))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) taskTypes(taskTypes []client.TaskType) error {
+	if p.json {
+		return p.writeJSON(taskTypes, "  ")
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPAYLOAD SCHEMA\tRESULT SCHEMA\tCACHED\tTIMEOUT\tMAX TIMEOUT\tDESCRIPTION")
+	for _, taskType := range taskTypes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			taskType.Name, yesOrNone(taskType.PayloadSchema != nil), yesOrNone(taskType.ResultSchema != nil), yesOrNone(taskType.Deterministic),
+			orNone(taskType.Timeout), orNone(taskType.MaxTimeout), orNone(taskType.Description))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) blob(blob *client.Blob) error {
+	if p.json {
+		return p.writeJSON(blob, "  ")
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIGEST\tSIZE\tREF")
+	fmt.Fprintf(tw, "%s\t%d\t%s\n", blob.Digest, blob.Size, blob.Ref)
+	return tw.Flush()
+}
+
+// event prints one line per task event, as JSON lines in json mode.
+func (p *printer) event(task *client.Task) error {
+	if p.json {
+		return p.writeJSON(task, "")
+	}
+
+	_, err := fmt.Fprintf(p.w, "%s  %-9s  %s  %s  %s\n",
+		time.Now().Format("15:04:05"), task.State, task.ID, task.TaskType, outcome(task))
+	return err
+}
+
+func (p *printer) writeJSON(v interface{}, indent string) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", indent)
+	return enc.Encode(v)
+}
+
+// outcome is the task's result once it has succeeded, or its error, on a
+// single line.
+func outcome(task *client.Task) string {
+	text := task.Result
+	switch {
+	case task.Error != "":
+		text = task.Error
+	case task.ResultBase64 != nil:
+		return fmt.Sprintf("<%d bytes of %s>", len(task.ResultBase64), task.ResultContentType)
+	case task.ResultJSON != nil:
+		text = string(task.ResultJSON)
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func yesOrNone(ok bool) string {
+	if ok {
+		return "yes"
+	}
+	return "-"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -1,30 +1,65 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
 	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
 	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/master"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"google.golang.org/grpc"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(validateConfig(os.Args[2:]))
+	}
+
 	configFile := flag.String("config", "config.yml", "Path to configuration file")
+	reloadInterval := flag.Duration("reload-interval", 5*time.Second, "How often to check the configuration file for changes (0 disables, SIGHUP still reloads)")
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration and the source of each value, then exit")
+	overrides := config.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
-	// Load configuration
-	cfg, err := config.LoadConfig(*configFile)
+	// The default config file is optional so the master can be configured
+	// from the environment alone
+	filename := *configFile
+	if !isFlagSet("config") {
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			filename = ""
+		}
+	}
+
+	// Load configuration: defaults, file, DS_* environment variables, flags
+	loader := &config.Loader{Filename: filename, Flags: overrides()}
+	cfg, sources, err := loader.Load()
 	if err != nil {
 		logger.GetLogger().Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if *printConfig {
+		if err := config.PrintConfig(os.Stdout, cfg, sources); err != nil {
+			logger.GetLogger().Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
+
 	// Initialize logger with configured level
 	logger.Init(cfg.Logging.Level)
 
-	logger.GetLogger().Infof("Loaded configuration from %s", *configFile)
+	if filename != "" {
+		logger.GetLogger().Infof("Loaded configuration from %s", filename)
+	} else {
+		logger.GetLogger().Info("No configuration file, using defaults, environment and flags")
+	}
 	logger.GetLogger().Infof("Server will start on %s", cfg.GetServerAddress())
 	logger.GetLogger().Infof("Configured workers: %d", len(cfg.Workers))
 
@@ -35,8 +70,48 @@ func main() {
 	}
 	defer workerPool.Close()
 
+	// Save workers added or removed through the admin API to the file
+	if cfg.Admin.PersistWorkers {
+		switch {
+		case filename == "":
+			logger.GetLogger().Warn("admin.persist_workers is set but there is no configuration file")
+		case sources["workers"] == config.SourceEnv, sources["workers"] == config.SourceFlag:
+			// Saving would write a list the next start ignores
+			logger.GetLogger().Warnf("admin.persist_workers is set but workers come from the %s, which overrides the file; not saving worker changes", sources["workers"])
+		default:
+			workerPool.SetPersist(func(workers []config.WorkerConfig) error {
+				return config.SaveWorkers(filename, workers)
+			})
+		}
+	}
+
+	// Initialize blob store
+	blobs, err := master.NewFileBlobStore(cfg.Blobs.Dir)
+	if err != nil {
+		logger.GetLogger().Fatalf("Failed to initialize blob store: %v", err)
+	}
+
+	// Initialize task manager
+	tasks := master.NewTaskManager(workerPool, blobs)
+	defer tasks.Close()
+
+	// Initialize scheduler
+	scheduler, err := master.NewScheduler(tasks, cfg)
+	if err != nil {
+		logger.GetLogger().Fatalf("Failed to initialize scheduler: %v", err)
+	}
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	// Watch the configuration file and apply changes without restarting
+	watcher := config.NewWatcher(loader, *reloadInterval, func(newCfg *config.Config) {
+		applyConfig(workerPool, tasks, newCfg)
+	})
+	watcher.Start()
+	defer watcher.Stop()
+
 	// Setup HTTP server
-	router := master.SetupRoutes(workerPool, cfg)
+	router := master.SetupRoutes(workerPool, tasks, scheduler, cfg)
 
 	// Start server
 	logger.GetLogger().Infof("Master starting HTTP server on %s", cfg.GetServerAddress())
@@ -46,10 +121,143 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt signal
+	// Start the gRPC API alongside REST
+	if addr := cfg.GetGRPCAddress(); addr != "" {
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			logger.GetLogger().Fatalf("Failed to listen on %s: %v", addr, err)
+		}
+
+		grpcServer := grpc.NewServer(master.ServerOptions(cfg)...)
+		pb.RegisterMasterServiceServer(grpcServer, master.NewMasterServer(workerPool, tasks))
+		defer func() {
+			// Watch streams of delayed tasks may stay open for long, so
+			// give in-flight calls a moment and then close the rest
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				grpcServer.Stop()
+			}
+		}()
+
+		logger.GetLogger().Infof("Master starting gRPC server on %s", addr)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.GetLogger().Fatalf("Failed to serve gRPC: %v", err)
+			}
+		}()
+	}
+
+	// Wait for interrupt signal, reloading the configuration on SIGHUP
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range c {
+		if sig == syscall.SIGHUP {
+			logger.GetLogger().Info("Received SIGHUP, reloading configuration")
+			watcher.Reload()
+			continue
+		}
+		break
+	}
 
 	logger.GetLogger().Info("Master shutting down...")
 }
+
+// validateConfig implements the validate-config subcommand: it loads the
+// configuration like the master would and lists every problem found,
+// returning the process exit code.
+func validateConfig(args []string) int {
+	fs := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate-config [flags] [config file]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	configFile := fs.String("config", "config.yml", "Path to configuration file")
+	overrides := config.RegisterFlags(fs)
+	fs.Parse(args)
+
+	filename := *configFile
+	if fs.NArg() > 0 {
+		filename = fs.Arg(0)
+	}
+
+	loader := &config.Loader{Filename: filename, Flags: overrides()}
+	if _, _, err := loader.Load(); err != nil {
+		var problems config.ValidationErrors
+		if !errors.As(err, &problems) {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filename, err)
+			return 1
+		}
+		for _, problem := range problems {
+			location := filename
+			if problem.Line > 0 {
+				location = fmt.Sprintf("%s:%d", filename, problem.Line)
+			}
+			problem.Line = 0
+			fmt.Fprintf(os.Stderr, "%s: %s\n", location, problem.Error())
+		}
+		return 1
+	}
+
+	fmt.Printf("%s: configuration is valid\n", filename)
+	return 0
+}
+
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// applyConfig applies a reloaded configuration to the running master. Server
+// address and scheduler settings only take effect after a restart.
+func applyConfig(workerPool *master.WorkerPool, tasks *master.TaskManager, newCfg *config.Config) {
+	oldCfg := workerPool.Config()
+
+	if err := logger.SetLevel(newCfg.Logging.Level); err != nil {
+		logger.GetLogger().Errorf("Failed to apply logging level %s: %v", newCfg.Logging.Level, err)
+	}
+
+	workerPool.Reconcile(newCfg)
+
+	if err := tasks.UpdateTaskTypes(newCfg.TaskTypes); err != nil {
+		logger.GetLogger().Errorf("Failed to apply task types: %v", err)
+	}
+
+	if newCfg.GetServerAddress() != oldCfg.GetServerAddress() {
+		logger.GetLogger().Warnf("Server address change to %s requires a restart", newCfg.GetServerAddress())
+	}
+	if newCfg.GetGRPCAddress() != oldCfg.GetGRPCAddress() {
+		logger.GetLogger().Warnf("gRPC address change to %q requires a restart", newCfg.GetGRPCAddress())
+	}
+	if newCfg.GRPC.TLS != oldCfg.GRPC.TLS {
+		logger.GetLogger().Warn("gRPC TLS changes require a restart")
+	}
+	if newCfg.GetMaxMessageSize() != oldCfg.GetMaxMessageSize() {
+		logger.GetLogger().Warn("gRPC max_message_size changes require a restart")
+	}
+	if newCfg.Admin != oldCfg.Admin {
+		logger.GetLogger().Warn("Admin configuration changes require a restart")
+	}
+	if newCfg.Scheduler != oldCfg.Scheduler {
+		logger.GetLogger().Warn("Scheduler configuration changes require a restart")
+	}
+	if newCfg.ResultCache != oldCfg.ResultCache {
+		logger.GetLogger().Warn("Result cache configuration changes require a restart")
+	}
+	if newCfg.Blobs != oldCfg.Blobs {
+		logger.GetLogger().Warn("Blob store configuration changes require a restart")
+	}
+
+	logger.GetLogger().Infof("Configuration reloaded: %d workers, gRPC timeout %s, log level %s",
+		len(newCfg.Workers), newCfg.GetGRPCTimeout(), newCfg.Logging.Level)
+}
@@ -1,40 +1,101 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
 	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
 	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/worker"
 	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
-	port := flag.Int("port", 50051, "gRPC server port")
+	configFile := flag.String("config", "", "Path to worker configuration file")
+	port := flag.Int("port", 50051, "gRPC server port (overrides listen)")
 	workerID := flag.String("id", "worker-1", "Worker ID")
 	logLevel := flag.String("log-level", "info", "Logging level (debug, info, warn, error, fatal)")
+	taskTypes := flag.String("task-types", strings.Join(config.DefaultTaskTypes, ","), "Comma-separated task types this worker accepts")
+	labels := flag.String("labels", "", "Comma-separated key=value labels advertised to the master")
+	concurrency := flag.Int("concurrency", 0, "Maximum tasks processed at once (0 for no limit)")
+	masterAddr := flag.String("master", "", "Master HTTP address (host:port) to register with")
+	advertise := flag.String("advertise", "", "Address the master dials to reach this worker")
+	blobSource := flag.String("blob-source", "", "Master HTTP address (host:port) to fetch blobs from, defaults to -master")
 	flag.Parse()
 
+	// Load configuration; flags given on the command line override the file
+	cfg := config.DefaultWorkerNodeConfig()
+	if *configFile != "" {
+		var err error
+		cfg, err = config.LoadWorkerConfig(*configFile)
+		if err != nil {
+			logger.GetLogger().Fatalf("Failed to load configuration: %v", err)
+		}
+	}
+
+	var labelsErr error
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Listen = fmt.Sprintf(":%d", *port)
+		case "id":
+			cfg.ID = *workerID
+		case "log-level":
+			cfg.Logging.Level = *logLevel
+		case "task-types":
+			cfg.TaskTypes = splitList(*taskTypes)
+		case "labels":
+			cfg.Labels, labelsErr = parseLabels(*labels)
+		case "concurrency":
+			cfg.Concurrency = *concurrency
+		case "master":
+			cfg.Master.Address = *masterAddr
+		case "advertise":
+			cfg.Master.Advertise = *advertise
+		case "blob-source":
+			cfg.BlobCache.Source = *blobSource
+		}
+	})
+	if labelsErr != nil {
+		logger.GetLogger().Fatalf("Invalid labels: %v", labelsErr)
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.GetLogger().Fatalf("Invalid configuration: %v", err)
+	}
+
 	// Initialize logger
-	logger.Init(*logLevel)
+	logger.Init(cfg.Logging.Level)
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+	lis, err := net.Listen("tcp", cfg.Listen)
 	if err != nil {
 		logger.GetLogger().Fatalf("Failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
-	workerServer := worker.NewWorkerServer(*workerID)
+	opts := worker.ServerOptions(cfg)
+	if cfg.TLS.Enabled() {
+		tlsConfig, err := cfg.TLS.ServerTLS()
+		if err != nil {
+			logger.GetLogger().Fatalf("Failed to set up TLS: %v", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	grpcServer := grpc.NewServer(opts...)
+	workerServer := worker.NewWorkerServer(cfg)
 
 	pb.RegisterWorkerServiceServer(grpcServer, workerServer)
 
-	logger.GetLogger().Infof("Worker %s starting gRPC server on port %d", *workerID, *port)
+	logger.GetLogger().Infof("Worker %s starting gRPC server on %s (task types: %s, TLS: %t)",
+		cfg.ID, cfg.Listen, strings.Join(cfg.TaskTypes, ","), cfg.TLS.Enabled())
 
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
@@ -42,11 +103,50 @@ func main() {
 		}
 	}()
 
+	// Register with the master when one is configured
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var registrar *worker.Registrar
+	if cfg.Master.Address != "" {
+		registrar = worker.NewRegistrar(cfg)
+		go registrar.Run(ctx)
+	}
+
 	// Wait for interrupt signal
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
 
 	logger.GetLogger().Info("Worker shutting down...")
+	cancel()
+	if registrar != nil {
+		// Leave the pool first so the master stops sending new tasks
+		if err := registrar.Deregister(); err != nil {
+			logger.GetLogger().Warnf("Failed to deregister from master: %v", err)
+		}
+	}
 	grpcServer.GracefulStop()
+	workerServer.Close()
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseLabels(value string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range splitList(value) {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("label %q must be in key=value form", pair)
+		}
+		labels[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return labels, nil
 }
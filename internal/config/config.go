@@ -2,90 +2,294 @@ package config
 
 import (
 	"fmt"
-	"os"
+	"strconv"
 	"time"
 
-	"gopkg.in/yaml.v3"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/jsonschema"
 )
 
 const (
-	DefaultGRPCTimeout = 10 * time.Second
+	DefaultGRPCTimeout          = 10 * time.Second
+	DefaultScheduleHistoryLimit = 50
+	// DefaultMaxMessageSize matches gRPC's own limit
+	DefaultMaxMessageSize = 4 * 1024 * 1024
+	// DefaultChunkSize leaves ample room under the message size limit
+	DefaultChunkSize = 1024 * 1024
+	DefaultBlobDir   = "data/blobs"
+
+	DefaultResultCacheTTL  = 10 * time.Minute
+	DefaultResultCacheSize = 64 * 1024 * 1024
+
+	DefaultTaskRetention    = time.Hour
+	DefaultMaxFinishedTasks = 10000
 )
 
 type Config struct {
-	Server  ServerConfig   `yaml:"server"`
-	Workers []WorkerConfig `yaml:"workers"`
-	GRPC    GRPCConfig     `yaml:"grpc"`
-	Logging LoggingConfig  `yaml:"logging"`
+	Server    ServerConfig    `yaml:"server"`
+	Workers   []WorkerConfig  `yaml:"workers"`
+	GRPC      GRPCConfig      `yaml:"grpc"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Blobs     BlobsConfig     `yaml:"blobs"`
+	// Tasks bounds how long finished tasks are kept.
+	Tasks TasksConfig `yaml:"tasks"`
+	// ResultCache holds the results of deterministic task types.
+	ResultCache ResultCacheConfig `yaml:"result_cache"`
+	// TaskTypes is the catalog of declared task types, published at
+	// GET /task-types. Types that are not declared are not validated.
+	TaskTypes []TaskTypeConfig `yaml:"task_types,omitempty"`
 }
 
 type ServerConfig struct {
 	Port string `yaml:"port"`
 	Host string `yaml:"host"`
+	// GRPCPort serves the master's own gRPC API; empty disables it.
+	GRPCPort string `yaml:"grpc_port"`
 }
 
 type WorkerConfig struct {
 	URL string `yaml:"url"`
 	ID  string `yaml:"id"`
+	// TaskTypes and Labels override what the worker advertises through
+	// GetStatus. A worker with no known task types accepts every type.
+	TaskTypes []string          `yaml:"task_types,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// TaskTypeConfig declares a task type. The schemas are JSON Schema documents,
+// written in YAML; payloads are checked before dispatch and results once the
+// worker returns them.
+type TaskTypeConfig struct {
+	Name          string                 `yaml:"name"`
+	Description   string                 `yaml:"description,omitempty"`
+	PayloadSchema map[string]interface{} `yaml:"payload_schema,omitempty"`
+	ResultSchema  map[string]interface{} `yaml:"result_schema,omitempty"`
+	// Deterministic task types always return the same result for the same
+	// payload, so their results are cached and reused.
+	Deterministic bool `yaml:"deterministic,omitempty"`
+	// Timeout is the default deadline of the type's tasks, instead of
+	// grpc.timeout, and MaxTimeout the longest one a submission may ask for.
+	Timeout    string `yaml:"timeout,omitempty"`
+	MaxTimeout string `yaml:"max_timeout,omitempty"`
 }
 
 type GRPCConfig struct {
 	Timeout    string `yaml:"timeout"`
 	MaxRetries int    `yaml:"max_retries"`
+	// MaxMessageSize limits the gRPC messages exchanged with workers and
+	// clients, in bytes. Payloads and results larger than ChunkSize are
+	// streamed to and from workers in chunks of that size instead.
+	MaxMessageSize int `yaml:"max_message_size"`
+	ChunkSize      int `yaml:"chunk_size"`
+	// TLS secures the connections to workers; plaintext when not enabled.
+	TLS TLSConfig `yaml:"tls"`
 }
 
 type LoggingConfig struct {
 	Level string `yaml:"level"`
 }
 
-func LoadConfig(filename string) (*Config, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
+type SchedulerConfig struct {
+	// StorePath is the JSON file schedules and their run history are
+	// persisted to. When empty, schedules are kept in memory only.
+	StorePath    string `yaml:"store_path"`
+	HistoryLimit int    `yaml:"history_limit"`
+}
 
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
+// BlobsConfig configures the blob store payloads can refer to.
+type BlobsConfig struct {
+	// Dir is the directory blobs are stored in, named by their digest.
+	Dir string `yaml:"dir"`
+}
 
-	// Validate configuration
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
-	}
+type ResultCacheConfig struct {
+	// TTL is how long a result is reused.
+	TTL string `yaml:"ttl"`
+	// MaxSize bounds the cached results in bytes, evicting the least
+	// recently used ones first.
+	MaxSize int `yaml:"max_size"`
+}
+
+// TasksConfig bounds the finished tasks the master keeps in memory, along
+// with their payloads, results and idempotency keys.
+type TasksConfig struct {
+	// Retention is how long a finished task can still be fetched.
+	Retention string `yaml:"retention"`
+	// MaxFinished bounds the finished tasks kept, evicting the ones that
+	// finished first.
+	MaxFinished int `yaml:"max_finished"`
+}
+
+// AdminConfig configures the admin API that manages workers at runtime.
+type AdminConfig struct {
+	// PersistWorkers writes workers added or removed through the admin API
+	// back to the configuration file.
+	PersistWorkers bool `yaml:"persist_workers"`
+}
 
-	return &config, nil
+// LoadConfig reads the configuration from a YAML file over the defaults,
+// ignoring environment variables and flags. See Loader for the full layering.
+func LoadConfig(filename string) (*Config, error) {
+	loader := &Loader{Filename: filename, Env: []string{}}
+	cfg, _, err := loader.Load()
+	return cfg, err
 }
 
+// Validate checks the whole configuration and returns every problem found
+// as ValidationErrors.
 func (c *Config) Validate() error {
-	if c.Server.Port == "" {
-		return fmt.Errorf("server port is required")
+	v := &validator{}
+
+	validatePort(v, "server.port", c.Server.Port)
+	if c.Server.GRPCPort != "" {
+		validatePort(v, "server.grpc_port", c.Server.GRPCPort)
+		if c.Server.GRPCPort == c.Server.Port {
+			v.add("server.grpc_port", "must differ from server.port")
+		}
 	}
 
 	if len(c.Workers) == 0 {
-		return fmt.Errorf("at least one worker must be configured")
+		v.add("workers", "at least one worker must be configured")
 	}
 
+	ids := make(map[string]int, len(c.Workers))
+	urls := make(map[string]int, len(c.Workers))
 	for i, worker := range c.Workers {
+		key := fmt.Sprintf("workers[%d]", i)
 		if worker.URL == "" {
-			return fmt.Errorf("worker %d: URL is required", i)
+			v.add(key+".url", "is required")
+		} else if first, ok := urls[worker.URL]; ok {
+			v.add(key+".url", "duplicate worker URL %s (also workers[%d])", worker.URL, first)
+		} else {
+			urls[worker.URL] = i
 		}
 		if worker.ID == "" {
-			return fmt.Errorf("worker %d: ID is required", i)
+			v.add(key+".id", "is required")
+		} else if first, ok := ids[worker.ID]; ok {
+			v.add(key+".id", "duplicate worker ID %s (also workers[%d])", worker.ID, first)
+		} else {
+			ids[worker.ID] = i
+		}
+	}
+
+	validateDuration(v, "grpc.timeout", c.GRPC.Timeout)
+
+	if c.GRPC.MaxRetries < 0 {
+		v.add("grpc.max_retries", "must not be negative")
+	}
+
+	validateMessageSizes(v, "grpc.", c.GRPC.MaxMessageSize, c.GRPC.ChunkSize)
+
+	c.GRPC.TLS.validate(v, "grpc.tls")
+	validateLogLevel(v, "logging.level", c.Logging.Level)
+
+	if c.Scheduler.HistoryLimit < 0 {
+		v.add("scheduler.history_limit", "must not be negative")
+	}
+
+	if c.Blobs.Dir == "" {
+		v.add("blobs.dir", "is required")
+	}
+
+	validateDuration(v, "tasks.retention", c.Tasks.Retention)
+	if c.Tasks.MaxFinished < 0 {
+		v.add("tasks.max_finished", "must not be negative")
+	}
+
+	validateDuration(v, "result_cache.ttl", c.ResultCache.TTL)
+	if c.ResultCache.MaxSize < 0 {
+		v.add("result_cache.max_size", "must not be negative")
+	}
+
+	names := make(map[string]int, len(c.TaskTypes))
+	for i, taskType := range c.TaskTypes {
+		key := fmt.Sprintf("task_types[%d]", i)
+		if taskType.Name == "" {
+			v.add(key+".name", "is required")
+		} else if first, ok := names[taskType.Name]; ok {
+			v.add(key+".name", "duplicate task type %s (also task_types[%d])", taskType.Name, first)
+		} else {
+			names[taskType.Name] = i
+		}
+		if taskType.PayloadSchema != nil {
+			if _, err := jsonschema.Compile(taskType.PayloadSchema); err != nil {
+				v.add(key+".payload_schema", "%v", err)
+			}
+		}
+		if taskType.ResultSchema != nil {
+			if _, err := jsonschema.Compile(taskType.ResultSchema); err != nil {
+				v.add(key+".result_schema", "%v", err)
+			}
 		}
+		timeout := validateDuration(v, key+".timeout", taskType.Timeout)
+		maxTimeout := validateDuration(v, key+".max_timeout", taskType.MaxTimeout)
+		if timeout > 0 && maxTimeout > 0 && timeout > maxTimeout {
+			v.add(key+".timeout", "must not exceed max_timeout (%s)", taskType.MaxTimeout)
+		}
+	}
+
+	return v.err()
+}
+
+// validateDuration checks an optional duration, which must be positive when
+// set, and returns it, or 0 when it is unset or invalid.
+func validateDuration(v *validator, key, value string) time.Duration {
+	if value == "" {
+		return 0
 	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		v.add(key, "invalid duration %q", value)
+		return 0
+	}
+	if d <= 0 {
+		v.add(key, "must be positive")
+		return 0
+	}
+	return d
+}
+
+// validateMessageSizes checks that chunks fit in a message, with room to
+// spare for the fields around them.
+func validateMessageSizes(v *validator, prefix string, maxMessageSize, chunkSize int) {
+	if maxMessageSize < 0 {
+		v.add(prefix+"max_message_size", "must not be negative")
+	}
+	if chunkSize < 0 {
+		v.add(prefix+"chunk_size", "must not be negative")
+	}
+	if maxMessageSize == 0 {
+		maxMessageSize = DefaultMaxMessageSize
+	}
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if chunkSize > maxMessageSize/2 {
+		v.add(prefix+"chunk_size", "must be at most half of max_message_size (%d)", maxMessageSize)
+	}
+}
 
-	// Validate logging level
-	switch c.Logging.Level {
+func validatePort(v *validator, key, port string) {
+	if port == "" {
+		v.add(key, "is required")
+		return
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		v.add(key, "invalid port %q", port)
+	}
+}
+
+func validateLogLevel(v *validator, key, level string) {
+	switch level {
 	case "debug", "info", "warn", "error", "fatal", "": // "" allows for default
-		// Valid
 	default:
-		return fmt.Errorf("invalid logging level: %s. Must be one of debug, info, warn, error, fatal", c.Logging.Level)
+		v.add(key, "invalid logging level %s. Must be one of debug, info, warn, error, fatal", level)
 	}
-
-	return nil
 }
 
+// GetGRPCTimeout returns the configured timeout, or the default when it is
+// unset. Validate rejects timeouts that do not parse.
 func (c *Config) GetGRPCTimeout() time.Duration {
 	if c.GRPC.Timeout == "" {
 		return DefaultGRPCTimeout
@@ -99,6 +303,58 @@ func (c *Config) GetGRPCTimeout() time.Duration {
 	return timeout
 }
 
+// GetMaxMessageSize returns the gRPC message size limit, or the default
+// when it is unset.
+func (c *Config) GetMaxMessageSize() int {
+	if c.GRPC.MaxMessageSize == 0 {
+		return DefaultMaxMessageSize
+	}
+	return c.GRPC.MaxMessageSize
+}
+
+// GetChunkSize returns the size of streamed chunks, or the default when it is
+// unset.
+func (c *Config) GetChunkSize() int {
+	if c.GRPC.ChunkSize == 0 {
+		return DefaultChunkSize
+	}
+	return c.GRPC.ChunkSize
+}
+
+// GetTaskRetention returns how long finished tasks are kept, or the default
+// when it is unset.
+func (c *Config) GetTaskRetention() time.Duration {
+	retention, err := time.ParseDuration(c.Tasks.Retention)
+	if err != nil {
+		return DefaultTaskRetention
+	}
+	return retention
+}
+
+func (c *Config) GetMaxFinishedTasks() int {
+	if c.Tasks.MaxFinished == 0 {
+		return DefaultMaxFinishedTasks
+	}
+	return c.Tasks.MaxFinished
+}
+
+// GetResultCacheTTL returns how long results are cached, or the default when
+// it is unset.
+func (c *Config) GetResultCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(c.ResultCache.TTL)
+	if err != nil {
+		return DefaultResultCacheTTL
+	}
+	return ttl
+}
+
+func (c *Config) GetResultCacheSize() int {
+	if c.ResultCache.MaxSize == 0 {
+		return DefaultResultCacheSize
+	}
+	return c.ResultCache.MaxSize
+}
+
 func (c *Config) GetServerAddress() string {
 	if c.Server.Host == "" {
 		return ":" + c.Server.Port
@@ -106,6 +362,15 @@ func (c *Config) GetServerAddress() string {
 	return c.Server.Host + ":" + c.Server.Port
 }
 
+// GetGRPCAddress returns the address of the master's gRPC API, or "" when
+// it is disabled.
+func (c *Config) GetGRPCAddress() string {
+	if c.Server.GRPCPort == "" {
+		return ""
+	}
+	return c.Server.Host + ":" + c.Server.GRPCPort
+}
+
 func (c *Config) GetWorkerURLs() []string {
 	urls := make([]string, len(c.Workers))
 	for i, worker := range c.Workers {
@@ -113,3 +378,10 @@ func (c *Config) GetWorkerURLs() []string {
 	}
 	return urls
 }
+
+func (c *Config) GetScheduleHistoryLimit() int {
+	if c.Scheduler.HistoryLimit == 0 {
+		return DefaultScheduleHistoryLimit
+	}
+	return c.Scheduler.HistoryLimit
+}
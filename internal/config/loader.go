@@ -0,0 +1,349 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// EnvPrefix prefixes the environment variable of every configuration key,
+// e.g. DS_SERVER_PORT for server.port.
+const EnvPrefix = "DS_"
+
+// Source identifies the configuration layer a value came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Sources maps each configuration key to the layer that set its value.
+type Sources map[string]Source
+
+// Defaults returns the configuration used before any layer is applied.
+func Defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:     "8080",
+			GRPCPort: "9090",
+		},
+		GRPC: GRPCConfig{
+			Timeout:        DefaultGRPCTimeout.String(),
+			MaxMessageSize: DefaultMaxMessageSize,
+			ChunkSize:      DefaultChunkSize,
+		},
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+		Scheduler: SchedulerConfig{
+			HistoryLimit: DefaultScheduleHistoryLimit,
+		},
+		Blobs: BlobsConfig{
+			Dir: DefaultBlobDir,
+		},
+		Tasks: TasksConfig{
+			Retention:   DefaultTaskRetention.String(),
+			MaxFinished: DefaultMaxFinishedTasks,
+		},
+		ResultCache: ResultCacheConfig{
+			TTL:     DefaultResultCacheTTL.String(),
+			MaxSize: DefaultResultCacheSize,
+		},
+	}
+}
+
+// Loader builds a Config from layers, each overriding the previous one:
+// defaults, the YAML file, DS_* environment variables and command-line flags.
+type Loader struct {
+	// Filename is the YAML file to read; empty skips the file layer.
+	Filename string
+	// Env lists environment variables as KEY=value pairs; nil reads the
+	// process environment.
+	Env []string
+	// Flags holds the values of flags set on the command line by key.
+	Flags map[string]string
+}
+
+// Load applies every layer, validates the result and reports where each
+// value came from.
+func (l *Loader) Load() (*Config, Sources, error) {
+	cfg := Defaults()
+	sources := make(Sources)
+	for _, f := range configFields(cfg) {
+		sources[f.key] = SourceDefault
+	}
+
+	doc := &yamlDocument{lines: make(map[string]int)}
+	if l.Filename != "" {
+		data, err := os.ReadFile(l.Filename)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		doc, err = decodeStrict(data, cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		for _, f := range configFields(cfg) {
+			if _, ok := doc.lines[f.key]; ok {
+				sources[f.key] = SourceFile
+			}
+		}
+	}
+
+	env := l.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	envValues := make(map[string]string)
+	for _, kv := range env {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			envValues[name] = value
+		}
+	}
+
+	// Overrides that do not parse are reported along with the validation
+	// problems rather than on their own
+	var overrideErrs ValidationErrors
+	for _, f := range configFields(cfg) {
+		if value, ok := envValues[EnvName(f.key)]; ok {
+			if err := setField(f.value, value); err != nil {
+				overrideErrs = append(overrideErrs, ValidationError{Key: f.key, Msg: fmt.Sprintf("%s: %v", EnvName(f.key), err)})
+			}
+			sources[f.key] = SourceEnv
+		}
+		if value, ok := l.Flags[f.key]; ok {
+			if err := setField(f.value, value); err != nil {
+				overrideErrs = append(overrideErrs, ValidationError{Key: f.key, Msg: fmt.Sprintf("-%s: %v", f.key, err)})
+			}
+			sources[f.key] = SourceFlag
+		}
+	}
+
+	problems := doc.collect(cfg.Validate(), sources.fromFile)
+	problems = append(problems, overrideErrs...)
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", problems)
+	}
+
+	return cfg, sources, nil
+}
+
+// fromFile reports whether the file set the value at a validation path. The
+// path may lie inside a configuration key, such as workers[1].id, or contain
+// several keys, such as grpc.tls.
+func (s Sources) fromFile(path string) bool {
+	for key, source := range s {
+		if source != SourceFile {
+			continue
+		}
+		if path == key || strings.HasPrefix(path, key+".") || strings.HasPrefix(path, key+"[") ||
+			strings.HasPrefix(key, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvName returns the environment variable that overrides a key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Keys lists every configuration key in declaration order.
+func Keys() []string {
+	fields := configFields(Defaults())
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.key
+	}
+	return keys
+}
+
+// RegisterFlags defines a string flag named after every configuration key on
+// the flag set. After parsing, the returned function yields the flags that
+// were actually given, ready for Loader.Flags.
+func RegisterFlags(fs *flag.FlagSet) func() map[string]string {
+	values := make(map[string]*string)
+	for _, key := range Keys() {
+		values[key] = fs.String(key, "", fmt.Sprintf("Override %s (env %s)", key, EnvName(key)))
+	}
+
+	return func() map[string]string {
+		set := make(map[string]string)
+		fs.Visit(func(f *flag.Flag) {
+			if v, ok := values[f.Name]; ok {
+				set[f.Name] = *v
+			}
+		})
+		return set
+	}
+}
+
+// PrintConfig writes every effective value along with its source.
+func PrintConfig(w io.Writer, cfg *Config, sources Sources) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, f := range configFields(cfg) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.key, formatField(f.value), sources[f.key])
+	}
+	return tw.Flush()
+}
+
+type configField struct {
+	key   string
+	value reflect.Value
+}
+
+// configFields walks the yaml tags of the configuration and returns its leaf
+// values keyed by dotted path. Lists such as workers are single keys.
+func configFields(cfg *Config) []configField {
+	var fields []configField
+
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			key := prefix + name
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Struct {
+				walk(fv, key+".")
+				continue
+			}
+			fields = append(fields, configField{key: key, value: fv})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+
+	return fields
+}
+
+func setField(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", raw)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		switch v.Type().Elem() {
+		case reflect.TypeOf(""):
+			v.Set(reflect.ValueOf(splitList(raw)))
+		case reflect.TypeOf(WorkerConfig{}):
+			workers, err := parseWorkers(raw)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(workers))
+		case reflect.TypeOf(TaskTypeConfig{}):
+			return fmt.Errorf("task types can only be declared in the configuration file")
+		default:
+			return fmt.Errorf("unsupported list type %s", v.Type())
+		}
+	case reflect.Map:
+		m := make(map[string]string)
+		for _, pair := range splitList(raw) {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("%q must be in key=value form", pair)
+			}
+			m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		v.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func formatField(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Slice:
+		switch list := v.Interface().(type) {
+		case []WorkerConfig:
+			return formatWorkers(list)
+		case []TaskTypeConfig:
+			names := make([]string, len(list))
+			for i, taskType := range list {
+				names[i] = taskType.Name
+			}
+			return strings.Join(names, ",")
+		}
+		return strings.Join(v.Interface().([]string), ",")
+	case reflect.Map:
+		m := v.Interface().(map[string]string)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = k + "=" + m[k]
+		}
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// parseWorkers reads a worker list written as comma-separated id=url pairs,
+// e.g. "worker-1=localhost:50051,worker-2=localhost:50052".
+func parseWorkers(raw string) ([]WorkerConfig, error) {
+	var workers []WorkerConfig
+	for _, pair := range splitList(raw) {
+		id, url, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, errors.New("workers must be a comma-separated list of id=url pairs")
+		}
+		workers = append(workers, WorkerConfig{
+			ID:  strings.TrimSpace(id),
+			URL: strings.TrimSpace(url),
+		})
+	}
+	return workers, nil
+}
+
+func formatWorkers(workers []WorkerConfig) string {
+	pairs := make([]string, len(workers))
+	for i, w := range workers {
+		pairs[i] = w.ID + "=" + w.URL
+	}
+	return strings.Join(pairs, ",")
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
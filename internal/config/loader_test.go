@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(filename, []byte(data), 0o644); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+	return filename
+}
+
+const testConfig = `server:
+  port: "8081"
+  grpc_port: "9091"
+logging:
+  level: debug
+workers:
+  - id: worker-1
+    url: localhost:50051
+`
+
+func TestLoaderPrecedence(t *testing.T) {
+	filename := writeConfig(t, testConfig)
+
+	tests := []struct {
+		name       string
+		filename   string
+		env        []string
+		flags      map[string]string
+		port       string
+		portSource Source
+		level      string
+	}{
+		{
+			name:       "defaults",
+			env:        []string{"DS_WORKERS=worker-1=localhost:50051"},
+			port:       "8080",
+			portSource: SourceDefault,
+			level:      "info",
+		},
+		{
+			name:       "file over defaults",
+			filename:   filename,
+			port:       "8081",
+			portSource: SourceFile,
+			level:      "debug",
+		},
+		{
+			name:       "env over file",
+			filename:   filename,
+			env:        []string{"DS_SERVER_PORT=8082", "PATH=/bin"},
+			port:       "8082",
+			portSource: SourceEnv,
+			level:      "debug",
+		},
+		{
+			name:       "flags over env",
+			filename:   filename,
+			env:        []string{"DS_SERVER_PORT=8082", "DS_LOGGING_LEVEL=warn"},
+			flags:      map[string]string{"server.port": "8083"},
+			port:       "8083",
+			portSource: SourceFlag,
+			level:      "warn",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := test.env
+			if env == nil {
+				env = []string{}
+			}
+			loader := &Loader{Filename: test.filename, Env: env, Flags: test.flags}
+			cfg, sources, err := loader.Load()
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Server.Port != test.port || sources["server.port"] != test.portSource {
+				t.Errorf("server.port = %s from %s, want %s from %s", cfg.Server.Port, sources["server.port"], test.port, test.portSource)
+			}
+			if cfg.Logging.Level != test.level {
+				t.Errorf("logging.level = %s, want %s", cfg.Logging.Level, test.level)
+			}
+		})
+	}
+}
+
+func TestLoaderParsesOverrides(t *testing.T) {
+	loader := &Loader{
+		Env: []string{
+			"DS_WORKERS=worker-1=localhost:50051, worker-2=localhost:50052",
+			"DS_ADMIN_PERSIST_WORKERS=true",
+			"DS_GRPC_MAX_MESSAGE_SIZE=8388608",
+		},
+	}
+	cfg, sources, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := []WorkerConfig{{ID: "worker-1", URL: "localhost:50051"}, {ID: "worker-2", URL: "localhost:50052"}}
+	if !reflect.DeepEqual(cfg.Workers, want) {
+		t.Errorf("workers = %+v, want %+v", cfg.Workers, want)
+	}
+	if !cfg.Admin.PersistWorkers || cfg.GRPC.MaxMessageSize != 8388608 {
+		t.Errorf("admin.persist_workers = %t, grpc.max_message_size = %d", cfg.Admin.PersistWorkers, cfg.GRPC.MaxMessageSize)
+	}
+	if sources["workers"] != SourceEnv {
+		t.Errorf("workers from %s, want %s", sources["workers"], SourceEnv)
+	}
+}
+
+func TestLoaderReportsBadOverrides(t *testing.T) {
+	loader := &Loader{
+		Env:   []string{"DS_WORKERS=worker-1=localhost:50051", "DS_GRPC_MAX_MESSAGE_SIZE=big"},
+		Flags: map[string]string{"admin.persist_workers": "maybe"},
+	}
+	_, _, err := loader.Load()
+
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Load error = %v, want ValidationErrors", err)
+	}
+	keys := make(map[string]bool)
+	for _, problem := range problems {
+		keys[problem.Key] = true
+	}
+	for _, key := range []string{"grpc.max_message_size", "admin.persist_workers"} {
+		if !keys[key] {
+			t.Errorf("no problem reported for %s in %v", key, problems)
+		}
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("master", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse([]string{"-server.port", "8083", "-workers", "worker-1=localhost:50051"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Only the flags given on the command line override other layers
+	want := map[string]string{"server.port": "8083", "workers": "worker-1=localhost:50051"}
+	if got := flags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("flags = %v, want %v", got, want)
+	}
+}
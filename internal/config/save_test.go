@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveWorkers(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "replaces the list",
+			data: `# Master configuration
+server:
+  port: "8081" # HTTP
+workers:
+  - id: worker-1
+    url: localhost:50051
+logging:
+  level: debug
+`,
+		},
+		{
+			name: "adds the list",
+			data: `# Master configuration
+server:
+  port: "8081" # HTTP
+logging:
+  level: debug
+`,
+		},
+	}
+	workers := []WorkerConfig{
+		{ID: "worker-2", URL: "localhost:50052", TaskTypes: []string{"compute"}},
+		{ID: "worker-3", URL: "localhost:50053", Labels: map[string]string{"zone": "a"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := writeConfig(t, test.data)
+			if err := SaveWorkers(filename, workers); err != nil {
+				t.Fatalf("SaveWorkers: %v", err)
+			}
+
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("reading config: %v", err)
+			}
+			for _, comment := range []string{"# Master configuration", "# HTTP"} {
+				if !strings.Contains(string(data), comment) {
+					t.Errorf("comment %q lost:\n%s", comment, data)
+				}
+			}
+
+			cfg, err := LoadConfig(filename)
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if cfg.Server.Port != "8081" || cfg.Logging.Level != "debug" {
+				t.Errorf("server.port = %s, logging.level = %s, want 8081 and debug", cfg.Server.Port, cfg.Logging.Level)
+			}
+			if !reflect.DeepEqual(cfg.Workers, workers) {
+				t.Errorf("workers = %+v, want %+v", cfg.Workers, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig holds the certificate files used to secure gRPC connections.
+// On a worker, CertFile and KeyFile are its server certificate and CAFile,
+// when set, verifies client certificates. On the master, CAFile verifies
+// the workers and CertFile and KeyFile are its client certificate.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	CAFile   string `yaml:"ca_file"`
+}
+
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.CAFile != ""
+}
+
+func (t TLSConfig) validate(v *validator, key string) {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		v.add(key, "cert_file and key_file must be set together")
+	}
+	files := []struct{ name, path string }{
+		{"cert_file", t.CertFile},
+		{"key_file", t.KeyFile},
+		{"ca_file", t.CAFile},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			v.add(key+"."+file.name, "%v", err)
+		}
+	}
+}
+
+// ServerTLS builds the TLS configuration of a gRPC server. Client
+// certificates are required when a CA is configured.
+func (t TLSConfig) ServerTLS() (*tls.Config, error) {
+	if t.CertFile == "" {
+		return nil, fmt.Errorf("tls cert_file is required to serve TLS")
+	}
+
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if t.CAFile != "" {
+		pool, err := loadCertPool(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// ClientTLS builds the TLS configuration of a gRPC client. Without a CA the
+// system roots verify the server.
+func (t TLSConfig) ClientTLS() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if t.CAFile != "" {
+		pool, err := loadCertPool(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
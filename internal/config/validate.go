@@ -0,0 +1,220 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a single configuration problem. Key is the dotted path
+// of the offending value, such as workers[1].id, and Line its line in the
+// YAML file when known.
+type ValidationError struct {
+	Key  string
+	Line int
+	Msg  string
+}
+
+func (e ValidationError) Error() string {
+	msg := e.Msg
+	if e.Key != "" {
+		msg = e.Key + ": " + msg
+	}
+	if e.Line > 0 {
+		msg = fmt.Sprintf("line %d: %s", e.Line, msg)
+	}
+	return msg
+}
+
+// ValidationErrors collects every problem found in a configuration.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return fmt.Sprintf("%d problems:\n  %s", len(e), strings.Join(lines, "\n  "))
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(key, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Key: key, Msg: fmt.Sprintf(format, args...)})
+}
+
+// err returns the collected problems, or nil when there are none.
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// yamlDocument records where each key of a YAML file sits and which keys do
+// not belong to the configuration struct.
+type yamlDocument struct {
+	lines    map[string]int
+	problems ValidationErrors
+}
+
+// decodeStrict decodes data over out, which must be a pointer to a struct.
+// Unknown keys and values of the wrong type are reported as problems rather
+// than stopping the decode, so every one of them is found in a single pass.
+func decodeStrict(data []byte, out interface{}) (*yamlDocument, error) {
+	doc := &yamlDocument{lines: make(map[string]int)}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return doc, nil
+	}
+
+	doc.walk(root.Content[0], reflect.TypeOf(out).Elem(), "")
+
+	if err := root.Decode(out); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, err
+		}
+		for _, msg := range typeErr.Errors {
+			problem := parseYAMLError(msg)
+			problem.Key = doc.keyAt(problem.Line)
+			doc.problems = append(doc.problems, problem)
+		}
+	}
+
+	return doc, nil
+}
+
+func (d *yamlDocument) walk(n *yaml.Node, t reflect.Type, path string) {
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			child := joinKey(path, key.Value)
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				d.problems = append(d.problems, ValidationError{Key: child, Line: key.Line, Msg: "unknown key"})
+				continue
+			}
+			d.lines[child] = key.Line
+			d.walk(n.Content[i+1], fieldType, child)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			child := joinKey(path, n.Content[i].Value)
+			d.lines[child] = n.Content[i].Line
+			d.walk(n.Content[i+1], t.Elem(), child)
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range n.Content {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			d.lines[child] = item.Line
+			d.walk(item, t.Elem(), child)
+		}
+	}
+}
+
+// keyAt returns the most specific key on a line.
+func (d *yamlDocument) keyAt(line int) string {
+	found := ""
+	for key, l := range d.lines {
+		if l == line && len(key) > len(found) {
+			found = key
+		}
+	}
+	return found
+}
+
+// line returns the line of a key, falling back to its closest parent that
+// appears in the file.
+func (d *yamlDocument) line(key string) int {
+	for key != "" {
+		if line, ok := d.lines[key]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(key, ".[")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return 0
+}
+
+// collect combines the problems found while decoding with those returned by
+// Validate, giving the latter the line of their key when fromFile reports the
+// key's value came from the file. Problems are ordered by line.
+func (d *yamlDocument) collect(validateErr error, fromFile func(key string) bool) ValidationErrors {
+	problems := append(ValidationErrors(nil), d.problems...)
+
+	var errs ValidationErrors
+	if errors.As(validateErr, &errs) {
+		for _, err := range errs {
+			if err.Line == 0 && fromFile(err.Key) {
+				err.Line = d.line(err.Key)
+			}
+			problems = append(problems, err)
+		}
+	} else if validateErr != nil {
+		problems = append(problems, ValidationError{Msg: validateErr.Error()})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line == 0 || problems[j].Line == 0 {
+			return problems[j].Line == 0 && problems[i].Line != 0
+		}
+		return problems[i].Line < problems[j].Line
+	})
+	return problems
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// parseYAMLError splits a yaml.v3 type error such as
+// "line 4: cannot unmarshal !!str `abc` into int" into its line and message.
+func parseYAMLError(msg string) ValidationError {
+	if rest, ok := strings.CutPrefix(msg, "line "); ok {
+		if num, text, ok := strings.Cut(rest, ": "); ok {
+			if line, err := strconv.Atoi(num); err == nil {
+				return ValidationError{Line: line, Msg: text}
+			}
+		}
+	}
+	return ValidationError{Msg: msg}
+}
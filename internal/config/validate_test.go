@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func problemsOf(t *testing.T, err error) ValidationErrors {
+	t.Helper()
+
+	var problems ValidationErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("error = %v, want ValidationErrors", err)
+	}
+	return problems
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		env  []string
+		want ValidationErrors
+	}{
+		{
+			name: "problems in file order",
+			data: `server:
+  port: "99999"
+grpc:
+  max_message_size: lots
+workers:
+  - id: worker-1
+    url: localhost:50051
+  - id: worker-1
+    url: localhost:50052
+`,
+			want: ValidationErrors{
+				{Key: "server.port", Line: 2, Msg: `invalid port "99999"`},
+				{Key: "grpc.max_message_size", Line: 4, Msg: "cannot unmarshal !!str `lots` into int"},
+				{Key: "workers[1].id", Line: 8, Msg: "duplicate worker ID worker-1 (also workers[0])"},
+			},
+		},
+		{
+			name: "unknown keys",
+			data: `server:
+  port: "8080"
+  prot: "8081"
+workers:
+  - id: worker-1
+    url: localhost:50051
+    weight: 2
+retries: 3
+`,
+			want: ValidationErrors{
+				{Key: "server.prot", Line: 3, Msg: "unknown key"},
+				{Key: "workers[0].weight", Line: 7, Msg: "unknown key"},
+				{Key: "retries", Line: 8, Msg: "unknown key"},
+			},
+		},
+		{
+			name: "overridden values have no line",
+			data: `logging:
+  level: loud
+workers:
+  - id: worker-1
+    url: localhost:50051
+`,
+			env: []string{"DS_SERVER_PORT=http"},
+			want: ValidationErrors{
+				{Key: "logging.level", Line: 2, Msg: "invalid logging level loud. Must be one of debug, info, warn, error, fatal"},
+				{Key: "server.port", Msg: `invalid port "http"`},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := test.env
+			if env == nil {
+				env = []string{}
+			}
+			loader := &Loader{Filename: writeConfig(t, test.data), Env: env}
+			_, _, err := loader.Load()
+			if got := problemsOf(t, err); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got problems\n%v\nwant\n%v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadWorkerConfigReportsEveryProblem(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "worker.yml")
+	data := `id: worker-1
+listen: ":50051"
+task_types: [compute]
+concurrency: -1
+handlers:
+  image:
+    delay: soon
+colour: blue
+`
+	if err := os.WriteFile(filename, []byte(data), 0o644); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	_, err := LoadWorkerConfig(filename)
+	got := problemsOf(t, err)
+	want := map[string]int{
+		"concurrency":          4,
+		"handlers.image":       6,
+		"handlers.image.delay": 7,
+		"colour":               8,
+	}
+	for _, problem := range got {
+		if line, ok := want[problem.Key]; ok {
+			if problem.Line != line {
+				t.Errorf("%s reported on line %d, want %d", problem.Key, problem.Line, line)
+			}
+			delete(want, problem.Key)
+		}
+	}
+	for key := range want {
+		t.Errorf("no problem reported for %s in %v", key, got)
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+)
+
+// Watcher reloads the configuration when its file changes or when a reload is
+// requested, passing each valid configuration to a callback. Environment
+// variables and flags are layered over the file again on every reload.
+// Invalid configurations are logged and otherwise ignored, so the previous
+// one stays in effect.
+type Watcher struct {
+	loader   *Loader
+	interval time.Duration
+	apply    func(*Config)
+
+	last   []byte
+	reload chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func NewWatcher(loader *Loader, interval time.Duration, apply func(*Config)) *Watcher {
+	var last []byte
+	if loader.Filename != "" {
+		last, _ = os.ReadFile(loader.Filename)
+	}
+
+	return &Watcher{
+		loader:   loader,
+		interval: interval,
+		apply:    apply,
+		last:     last,
+		reload:   make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start handles reloads in the background until Stop is called. The file is
+// polled for changes unless the interval is zero or there is no file.
+func (w *Watcher) Start() {
+	go func() {
+		defer close(w.done)
+
+		var tick <-chan time.Time
+		if w.interval > 0 && w.loader.Filename != "" {
+			ticker := time.NewTicker(w.interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-tick:
+				w.check(false)
+			case <-w.reload:
+				w.check(true)
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Reload asks the watcher to re-read the file even if it did not change.
+func (w *Watcher) Reload() {
+	select {
+	case w.reload <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Watcher) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+func (w *Watcher) check(force bool) {
+	if w.loader.Filename != "" {
+		data, err := os.ReadFile(w.loader.Filename)
+		if err != nil {
+			logger.GetLogger().Errorf("Failed to read configuration file %s: %v", w.loader.Filename, err)
+			return
+		}
+		if !force && bytes.Equal(data, w.last) {
+			return
+		}
+		w.last = data
+	}
+
+	cfg, _, err := w.loader.Load()
+	if err != nil {
+		logger.GetLogger().Errorf("Ignoring configuration change: %v", err)
+		return
+	}
+
+	logger.GetLogger().Info("Reloading configuration")
+	w.apply(cfg)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	filename := writeConfig(t, testConfig)
+	applied := make(chan *Config, 10)
+	w := NewWatcher(&Loader{Filename: filename, Env: []string{"DS_LOGGING_LEVEL=warn"}}, 10*time.Millisecond, func(cfg *Config) {
+		applied <- cfg
+	})
+	w.Start()
+	defer w.Stop()
+
+	next := func() *Config {
+		t.Helper()
+		select {
+		case cfg := <-applied:
+			return cfg
+		case <-time.After(5 * time.Second):
+			t.Fatal("configuration not reloaded")
+			return nil
+		}
+	}
+	// Replace the file in one step so a poll never sees it half written
+	write := func(data string) {
+		t.Helper()
+		tmp := filename + ".tmp"
+		if err := os.WriteFile(tmp, []byte(data), 0o644); err != nil {
+			t.Fatalf("writing config: %v", err)
+		}
+		if err := os.Rename(tmp, filename); err != nil {
+			t.Fatalf("writing config: %v", err)
+		}
+	}
+
+	// A change to the file is applied, with the environment layered over it
+	write(testConfig + "scheduler:\n  history_limit: 5\n")
+	if cfg := next(); cfg.Scheduler.HistoryLimit != 5 || cfg.Logging.Level != "warn" {
+		t.Errorf("history_limit = %d, logging.level = %s, want 5 and warn", cfg.Scheduler.HistoryLimit, cfg.Logging.Level)
+	}
+
+	// An invalid file is skipped; the next valid one is applied
+	write(testConfig + "scheduler:\n  history_limit: lots\n")
+	time.Sleep(50 * time.Millisecond)
+	write(testConfig + "scheduler:\n  history_limit: 7\n")
+	if cfg := next(); cfg.Scheduler.HistoryLimit != 7 {
+		t.Errorf("history_limit = %d, want 7", cfg.Scheduler.HistoryLimit)
+	}
+
+	// Reload re-reads the file even though it did not change
+	w.Reload()
+	if cfg := next(); cfg.Scheduler.HistoryLimit != 7 {
+		t.Errorf("history_limit = %d, want 7", cfg.Scheduler.HistoryLimit)
+	}
+	select {
+	case cfg := <-applied:
+		t.Errorf("unexpected reload with history_limit %d", cfg.Scheduler.HistoryLimit)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
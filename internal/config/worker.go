@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	DefaultWorkerListen = ":50051"
+	DefaultHandlerDelay = 2 * time.Second
+	// DefaultBlobCacheSize bounds the blobs a worker keeps on disk
+	DefaultBlobCacheSize = 256 * 1024 * 1024
+)
+
+// DefaultTaskTypes lists the task types a worker processes when its config
+// does not name any.
+var DefaultTaskTypes = []string{"compute", "process"}
+
+// WorkerNodeConfig configures a worker process, as opposed to WorkerConfig,
+// which describes a worker from the master's side.
+type WorkerNodeConfig struct {
+	Listen string `yaml:"listen"`
+	ID     string `yaml:"id"`
+	// Concurrency caps the tasks processed at once; 0 means no limit.
+	Concurrency int               `yaml:"concurrency"`
+	TaskTypes   []string          `yaml:"task_types"`
+	Labels      map[string]string `yaml:"labels"`
+	TLS         TLSConfig         `yaml:"tls"`
+	Master      MasterConfig      `yaml:"master"`
+	// MaxMessageSize limits the gRPC messages the worker exchanges, in bytes.
+	// Results larger than ChunkSize are streamed back in chunks of that size.
+	MaxMessageSize int `yaml:"max_message_size"`
+	ChunkSize      int `yaml:"chunk_size"`
+	// BlobCache keeps the blobs fetched from the master for payloads that
+	// refer to them.
+	BlobCache BlobCacheConfig `yaml:"blob_cache"`
+	// Handlers holds per task type settings, keyed by task type.
+	Handlers map[string]HandlerConfig `yaml:"handlers"`
+	Logging  LoggingConfig            `yaml:"logging"`
+}
+
+// MasterConfig tells a worker where to reach the master.
+type MasterConfig struct {
+	// Address is the host:port of the master's HTTP API the worker registers
+	// with. When empty, the worker waits to be listed in the master's config.
+	Address string `yaml:"address"`
+	// Advertise is the host:port the master dials to reach this worker. It
+	// defaults to the listen address, with the hostname filled in when the
+	// listen address has no host.
+	Advertise string `yaml:"advertise"`
+}
+
+type BlobCacheConfig struct {
+	// Source is the host:port of the master's HTTP API blobs are fetched
+	// from. It defaults to master.address, and lets a worker that does not
+	// register fetch blobs.
+	Source string `yaml:"source"`
+	// Dir defaults to a directory under the system's temporary directory.
+	Dir string `yaml:"dir"`
+	// MaxSize is how many bytes of blobs are kept, evicting the least
+	// recently used ones first.
+	MaxSize int64 `yaml:"max_size"`
+}
+
+type HandlerConfig struct {
+	// Delay is how long processing a task of this type takes.
+	Delay string `yaml:"delay"`
+}
+
+// DefaultWorkerNodeConfig returns the settings a worker runs with when it
+// has no config file.
+func DefaultWorkerNodeConfig() *WorkerNodeConfig {
+	return &WorkerNodeConfig{
+		Listen:    DefaultWorkerListen,
+		ID:        "worker-1",
+		TaskTypes: DefaultTaskTypes,
+		Logging: LoggingConfig{
+			Level: "info",
+		},
+	}
+}
+
+// LoadWorkerConfig reads a worker config file over the defaults.
+func LoadWorkerConfig(filename string) (*WorkerNodeConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config := DefaultWorkerNodeConfig()
+	doc, err := decodeStrict(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	problems := doc.collect(config.Validate(), func(string) bool { return true })
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %w", problems)
+	}
+
+	return config, nil
+}
+
+func (c *WorkerNodeConfig) Validate() error {
+	v := &validator{}
+
+	if _, port, err := net.SplitHostPort(c.Listen); err != nil {
+		v.add("listen", "invalid address %q", c.Listen)
+	} else {
+		validatePort(v, "listen", port)
+	}
+
+	if c.ID == "" {
+		v.add("id", "is required")
+	}
+
+	if c.Concurrency < 0 {
+		v.add("concurrency", "must not be negative")
+	}
+
+	if len(c.TaskTypes) == 0 {
+		v.add("task_types", "at least one task type must be configured")
+	}
+
+	for key := range c.Labels {
+		if key == "" {
+			v.add("labels", "label keys must not be empty")
+		}
+	}
+
+	c.TLS.validate(v, "tls")
+	validateMessageSizes(v, "", c.MaxMessageSize, c.ChunkSize)
+
+	if c.BlobCache.MaxSize < 0 {
+		v.add("blob_cache.max_size", "must not be negative")
+	}
+	if c.BlobCache.Source != "" {
+		if _, _, err := net.SplitHostPort(c.BlobCache.Source); err != nil {
+			v.add("blob_cache.source", "invalid address %q", c.BlobCache.Source)
+		}
+	}
+
+	if c.Master.Address != "" {
+		if _, _, err := net.SplitHostPort(c.Master.Address); err != nil {
+			v.add("master.address", "invalid address %q", c.Master.Address)
+		}
+	}
+	if c.Master.Advertise != "" {
+		if _, _, err := net.SplitHostPort(c.Master.Advertise); err != nil {
+			v.add("master.advertise", "invalid address %q", c.Master.Advertise)
+		}
+	}
+
+	for taskType, handler := range c.Handlers {
+		key := "handlers." + taskType
+		if !c.Supports(taskType) {
+			v.add(key, "task type %s is not in task_types", taskType)
+		}
+		if handler.Delay != "" {
+			if _, err := time.ParseDuration(handler.Delay); err != nil {
+				v.add(key+".delay", "invalid duration %q", handler.Delay)
+			}
+		}
+	}
+
+	validateLogLevel(v, "logging.level", c.Logging.Level)
+
+	return v.err()
+}
+
+func (c *WorkerNodeConfig) Supports(taskType string) bool {
+	for _, t := range c.TaskTypes {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAdvertiseAddress returns the address the master should dial.
+func (c *WorkerNodeConfig) GetAdvertiseAddress() string {
+	if c.Master.Advertise != "" {
+		return c.Master.Advertise
+	}
+
+	host, port, err := net.SplitHostPort(c.Listen)
+	if err != nil {
+		return c.Listen
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		if host, err = os.Hostname(); err != nil {
+			host = "localhost"
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func (c *WorkerNodeConfig) GetMaxMessageSize() int {
+	if c.MaxMessageSize == 0 {
+		return DefaultMaxMessageSize
+	}
+	return c.MaxMessageSize
+}
+
+func (c *WorkerNodeConfig) GetChunkSize() int {
+	if c.ChunkSize == 0 {
+		return DefaultChunkSize
+	}
+	return c.ChunkSize
+}
+
+// GetBlobCacheDir returns the blob cache directory, or the default when it is
+// unset.
+func (c *WorkerNodeConfig) GetBlobCacheDir() string {
+	if c.BlobCache.Dir == "" {
+		return filepath.Join(os.TempDir(), "ds-blob-cache")
+	}
+	return c.BlobCache.Dir
+}
+
+// GetBlobSource returns the master address blobs are fetched from, or ""
+// when the worker has none.
+func (c *WorkerNodeConfig) GetBlobSource() string {
+	if c.BlobCache.Source == "" {
+		return c.Master.Address
+	}
+	return c.BlobCache.Source
+}
+
+func (c *WorkerNodeConfig) GetBlobCacheSize() int64 {
+	if c.BlobCache.MaxSize == 0 {
+		return DefaultBlobCacheSize
+	}
+	return c.BlobCache.MaxSize
+}
+
+// GetHandlerDelay returns how long tasks of the type take to process.
+func (c *WorkerNodeConfig) GetHandlerDelay(taskType string) time.Duration {
+	handler, ok := c.Handlers[taskType]
+	if !ok || handler.Delay == "" {
+		return DefaultHandlerDelay
+	}
+
+	delay, err := time.ParseDuration(handler.Delay)
+	if err != nil {
+		return DefaultHandlerDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,458 @@
+// Package jsonschema validates decoded JSON values against a JSON Schema.
+// It implements the keywords task payloads need: type, enum, const,
+// properties, required, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, pattern, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, allOf, anyOf, oneOf and not. Other keywords, such as
+// title, description and format, are accepted and ignored; $ref is not
+// supported. Patterns are Go regular expressions (RE2), not ECMA-262 ones:
+// lookarounds and backreferences fail to compile.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error is a single violation. Path locates the offending value, such as
+// items[2].name, and is empty for the value itself.
+type Error struct {
+	Path    string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Errors collects every violation found in a value.
+type Errors []Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Prefix returns the errors with their paths under root, e.g. payload.
+func (e Errors) Prefix(root string) Errors {
+	prefixed := make(Errors, len(e))
+	for i, err := range e {
+		err.Path = joinPath(root, err.Path)
+		prefixed[i] = err
+	}
+	return prefixed
+}
+
+// Schema is a compiled schema.
+type Schema struct {
+	types     []string
+	enum      []interface{}
+	constant  interface{}
+	hasConst  bool
+	pattern   *regexp.Regexp
+	minLength *int
+	maxLength *int
+	minimum   *float64
+	maximum   *float64
+	exclMin   *float64
+	exclMax   *float64
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	noAdditional         bool
+
+	items    *Schema
+	minItems *int
+	maxItems *int
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+}
+
+// typeNames holds the known types, as used in messages.
+var typeNames = map[string]string{
+	"null":    "null",
+	"boolean": "a boolean",
+	"object":  "an object",
+	"array":   "an array",
+	"number":  "a number",
+	"integer": "an integer",
+	"string":  "a string",
+}
+
+// Compile checks a schema, given as decoded JSON or YAML, and prepares it for
+// validation.
+func Compile(schema map[string]interface{}) (*Schema, error) {
+	// Round-trip through JSON so YAML numbers and maps look like JSON ones
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return compile(doc, "")
+}
+
+func compile(doc interface{}, path string) (*Schema, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object", orRoot(path))
+	}
+
+	s := &Schema{}
+	var err error
+
+	switch t := m["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must list type names", joinPath(path, "type"))
+			}
+			s.types = append(s.types, name)
+		}
+	default:
+		return nil, fmt.Errorf("%s must be a string or a list", joinPath(path, "type"))
+	}
+	for _, name := range s.types {
+		if _, ok := typeNames[name]; !ok {
+			return nil, fmt.Errorf("%s: unknown type %q", joinPath(path, "type"), name)
+		}
+	}
+
+	if enum, ok := m["enum"]; ok {
+		if s.enum, ok = enum.([]interface{}); !ok {
+			return nil, fmt.Errorf("%s must be a list", joinPath(path, "enum"))
+		}
+	}
+	s.constant, s.hasConst = m["const"]
+
+	if pattern, ok := m["pattern"]; ok {
+		expr, ok := pattern.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a string", joinPath(path, "pattern"))
+		}
+		if s.pattern, err = regexp.Compile(expr); err != nil {
+			return nil, fmt.Errorf("%s: %w", joinPath(path, "pattern"), err)
+		}
+	}
+
+	for key, dst := range map[string]**int{
+		"minLength": &s.minLength, "maxLength": &s.maxLength,
+		"minItems": &s.minItems, "maxItems": &s.maxItems,
+	} {
+		if *dst, err = count(m, key, path); err != nil {
+			return nil, err
+		}
+	}
+	for key, dst := range map[string]**float64{
+		"minimum": &s.minimum, "maximum": &s.maximum,
+		"exclusiveMinimum": &s.exclMin, "exclusiveMaximum": &s.exclMax,
+	} {
+		if *dst, err = number(m, key, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if props, ok := m["properties"]; ok {
+		props, ok := props.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s must be an object", joinPath(path, "properties"))
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = compile(prop, joinPath(path, "properties."+name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if required, ok := m["required"]; ok {
+		list, ok := required.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list", joinPath(path, "required"))
+		}
+		for _, item := range list {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must list property names", joinPath(path, "required"))
+			}
+			s.required = append(s.required, name)
+		}
+	}
+
+	switch additional := m["additionalProperties"].(type) {
+	case nil:
+	case bool:
+		s.noAdditional = !additional
+	default:
+		if s.additionalProperties, err = compile(additional, joinPath(path, "additionalProperties")); err != nil {
+			return nil, err
+		}
+	}
+
+	if items, ok := m["items"]; ok {
+		if s.items, err = compile(items, joinPath(path, "items")); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, dst := range map[string]*[]*Schema{"allOf": &s.allOf, "anyOf": &s.anyOf, "oneOf": &s.oneOf} {
+		list, ok := m[key]
+		if !ok {
+			continue
+		}
+		schemas, ok := list.([]interface{})
+		if !ok || len(schemas) == 0 {
+			return nil, fmt.Errorf("%s must be a non-empty list", joinPath(path, key))
+		}
+		for i, item := range schemas {
+			sub, err := compile(item, fmt.Sprintf("%s[%d]", joinPath(path, key), i))
+			if err != nil {
+				return nil, err
+			}
+			*dst = append(*dst, sub)
+		}
+	}
+
+	if not, ok := m["not"]; ok {
+		if s.not, err = compile(not, joinPath(path, "not")); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func count(m map[string]interface{}, key, path string) (*int, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, fmt.Errorf("%s must be a non-negative integer", joinPath(path, key))
+	}
+	n := int(f)
+	return &n, nil
+}
+
+func number(m map[string]interface{}, key, path string) (*float64, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a number", joinPath(path, key))
+	}
+	return &f, nil
+}
+
+// Validate checks a value decoded with encoding/json and returns every
+// violation as Errors, or nil when the value matches.
+func (s *Schema) Validate(value interface{}) error {
+	var errs Errors
+	s.validate(value, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (s *Schema) validate(value interface{}, path string, errs *Errors) {
+	add := func(format string, args ...interface{}) {
+		*errs = append(*errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !s.matchesType(value) {
+		names := make([]string, len(s.types))
+		for i, name := range s.types {
+			names[i] = typeNames[name]
+		}
+		add("must be %s", strings.Join(names, " or "))
+		return
+	}
+
+	if s.enum != nil && !containsValue(s.enum, value) {
+		add("must be one of %s", formatValues(s.enum))
+	}
+	if s.hasConst && !reflect.DeepEqual(s.constant, value) {
+		add("must be %s", formatValues([]interface{}{s.constant}))
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			add("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			add("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			add("must match %s", s.pattern)
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			add("must be at least %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			add("must be at most %v", *s.maximum)
+		}
+		if s.exclMin != nil && v <= *s.exclMin {
+			add("must be greater than %v", *s.exclMin)
+		}
+		if s.exclMax != nil && v >= *s.exclMax {
+			add("must be less than %v", *s.exclMax)
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			add("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			add("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, Error{Path: joinPath(path, name), Message: "is required"})
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := joinPath(path, name)
+			if prop, ok := s.properties[name]; ok {
+				prop.validate(v[name], child, errs)
+				continue
+			}
+			switch {
+			case s.noAdditional:
+				*errs = append(*errs, Error{Path: child, Message: "is not allowed"})
+			case s.additionalProperties != nil:
+				s.additionalProperties.validate(v[name], child, errs)
+			}
+		}
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(value, path, errs)
+	}
+	if s.anyOf != nil && s.matching(s.anyOf, value) == 0 {
+		add("must match at least one of the allowed schemas")
+	}
+	if s.oneOf != nil && s.matching(s.oneOf, value) != 1 {
+		add("must match exactly one of the allowed schemas")
+	}
+	if s.not != nil && s.not.Validate(value) == nil {
+		add("must not match the excluded schema")
+	}
+}
+
+func (s *Schema) matchesType(value interface{}) bool {
+	for _, name := range s.types {
+		switch v := value.(type) {
+		case nil:
+			if name == "null" {
+				return true
+			}
+		case bool:
+			if name == "boolean" {
+				return true
+			}
+		case string:
+			if name == "string" {
+				return true
+			}
+		case float64:
+			if name == "number" || (name == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		case []interface{}:
+			if name == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if name == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Schema) matching(schemas []*Schema, value interface{}) int {
+	n := 0
+	for _, sub := range schemas {
+		if sub.Validate(value) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		data, _ := json.Marshal(v)
+		parts[i] = string(data)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinPath(path, key string) string {
+	switch {
+	case path == "":
+		return key
+	case key == "":
+		return path
+	case strings.HasPrefix(key, "["):
+		return path + key
+	}
+	return path + "." + key
+}
+
+func orRoot(path string) string {
+	if path == "" {
+		return "the schema"
+	}
+	return path
+}
@@ -0,0 +1,192 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func mustCompile(t *testing.T, schema string) *Schema {
+	t.Helper()
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &doc); err != nil {
+		t.Fatalf("bad test schema %s: %v", schema, err)
+	}
+	s, err := Compile(doc)
+	if err != nil {
+		t.Fatalf("Compile(%s): %v", schema, err)
+	}
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		// want lists the errors as path: message, in order
+		want []string
+	}{
+		{"empty schema", `{}`, `{"anything": [1, "a"]}`, nil},
+		{"ignored keywords", `{"title": "T", "description": "D", "format": "email"}`, `"not an email"`, nil},
+
+		{"type string", `{"type": "string"}`, `"a"`, nil},
+		{"type string mismatch", `{"type": "string"}`, `1`, []string{"must be a string"}},
+		{"type null", `{"type": "null"}`, `null`, nil},
+		{"type boolean", `{"type": "boolean"}`, `"true"`, []string{"must be a boolean"}},
+		{"type object", `{"type": "object"}`, `[]`, []string{"must be an object"}},
+		{"type array", `{"type": "array"}`, `{}`, []string{"must be an array"}},
+		{"type list", `{"type": ["string", "null"]}`, `null`, nil},
+		{"type list mismatch", `{"type": ["string", "null"]}`, `3`, []string{"must be a string or null"}},
+		{"number accepts fractions", `{"type": "number"}`, `1.5`, nil},
+		{"integer", `{"type": "integer"}`, `3`, nil},
+		{"integer accepts 1.0", `{"type": "integer"}`, `1.0`, nil},
+		{"integer rejects fractions", `{"type": "integer"}`, `1.5`, []string{"must be an integer"}},
+		{"integer rejects strings", `{"type": "integer"}`, `"3"`, []string{"must be an integer"}},
+		{"type mismatch stops other checks", `{"type": "string", "minLength": 5}`, `1`, []string{"must be a string"}},
+
+		{"enum", `{"enum": ["a", 1, null]}`, `1`, nil},
+		{"enum mismatch", `{"enum": ["a", 1]}`, `"b"`, []string{`must be one of "a", 1`}},
+		{"enum compares objects deeply", `{"enum": [{"a": [1]}]}`, `{"a": [1]}`, nil},
+		{"const", `{"const": {"k": true}}`, `{"k": true}`, nil},
+		{"const mismatch", `{"const": 3}`, `4`, []string{"must be 3"}},
+
+		{"minLength counts characters", `{"minLength": 3}`, `"héé"`, nil},
+		{"minLength", `{"minLength": 3}`, `"ab"`, []string{"must be at least 3 characters long"}},
+		{"maxLength", `{"maxLength": 2}`, `"abc"`, []string{"must be at most 2 characters long"}},
+		{"length ignores other types", `{"maxLength": 1}`, `12345`, nil},
+		{"pattern is unanchored", `{"pattern": "b+"}`, `"abbc"`, nil},
+		{"pattern anchored", `{"pattern": "^[a-z]+$"}`, `"ab1"`, []string{"must match ^[a-z]+$"}},
+		{"pattern digit class", `{"pattern": "^\\d{3}$"}`, `"123"`, nil},
+
+		{"minimum", `{"minimum": 2}`, `2`, nil},
+		{"minimum violated", `{"minimum": 2}`, `1.5`, []string{"must be at least 2"}},
+		{"maximum violated", `{"maximum": 2}`, `3`, []string{"must be at most 2"}},
+		{"exclusiveMinimum", `{"exclusiveMinimum": 2}`, `2`, []string{"must be greater than 2"}},
+		{"exclusiveMaximum", `{"exclusiveMaximum": 2}`, `2`, []string{"must be less than 2"}},
+		{"bounds ignore other types", `{"minimum": 10}`, `"1"`, nil},
+
+		{"minItems", `{"minItems": 2}`, `[1]`, []string{"must have at least 2 items"}},
+		{"maxItems", `{"maxItems": 1}`, `[1, 2]`, []string{"must have at most 1 items"}},
+		{"items", `{"items": {"type": "number"}}`, `[1, "a", 2, true]`, []string{"[1]: must be a number", "[3]: must be a number"}},
+
+		{"required", `{"required": ["a", "b"]}`, `{"a": 1}`, []string{"b: is required"}},
+		{"properties", `{"properties": {"a": {"type": "string"}}}`, `{"a": 1, "b": 2}`, []string{"a: must be a string"}},
+		{"additionalProperties false", `{"properties": {"a": {}}, "additionalProperties": false}`, `{"a": 1, "c": 2, "b": 3}`, []string{"b: is not allowed", "c: is not allowed"}},
+		{"additionalProperties true", `{"properties": {"a": {}}, "additionalProperties": true}`, `{"b": 3}`, nil},
+		{"additionalProperties schema", `{"properties": {"a": {}}, "additionalProperties": {"type": "integer"}}`, `{"a": "x", "b": 1, "c": "y"}`, []string{"c: must be an integer"}},
+		{"nested paths", `{"properties": {"items": {"items": {"properties": {"name": {"type": "string"}}, "required": ["id"]}}}}`,
+			`{"items": [{"id": 1, "name": "a"}, {"name": 2}]}`, []string{"items[1].id: is required", "items[1].name: must be a string"}},
+		{"every error is reported", `{"type": "object", "properties": {"a": {"minimum": 1}, "b": {"maxLength": 1}}, "required": ["c"]}`,
+			`{"a": 0, "b": "xx"}`, []string{"c: is required", "a: must be at least 1", "b: must be at most 1 characters long"}},
+
+		{"allOf", `{"allOf": [{"minimum": 1}, {"maximum": 3}]}`, `5`, []string{"must be at most 3"}},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "number"}]}`, `1`, nil},
+		{"anyOf mismatch", `{"anyOf": [{"type": "string"}, {"type": "number"}]}`, `true`, []string{"must match at least one of the allowed schemas"}},
+		{"oneOf", `{"oneOf": [{"type": "integer"}, {"type": "string"}]}`, `1`, nil},
+		{"oneOf none", `{"oneOf": [{"type": "integer"}, {"type": "string"}]}`, `true`, []string{"must match exactly one of the allowed schemas"}},
+		{"oneOf several", `{"oneOf": [{"type": "integer"}, {"type": "number"}]}`, `1`, []string{"must match exactly one of the allowed schemas"}},
+		{"not", `{"not": {"type": "string"}}`, `1`, nil},
+		{"not mismatch", `{"not": {"type": "string"}}`, `"a"`, []string{"must not match the excluded schema"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := mustCompile(t, tt.schema)
+
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatalf("bad test value %s: %v", tt.value, err)
+			}
+
+			err := s.Validate(value)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate(%s) = %v, want no error", tt.value, err)
+				}
+				return
+			}
+
+			errs, ok := err.(Errors)
+			if !ok {
+				t.Fatalf("Validate(%s) = %v, want Errors", tt.value, err)
+			}
+			got := make([]string, len(errs))
+			for i, e := range errs {
+				got[i] = e.Error()
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Validate(%s) errors:\n%s\nwant:\n%s", tt.value, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"unknown type", `{"type": "float"}`, `type: unknown type "float"`},
+		{"type not a string", `{"type": 1}`, "type must be a string or a list"},
+		{"type list of non-strings", `{"type": ["string", 1]}`, "type must list type names"},
+		{"enum not a list", `{"enum": "a"}`, "enum must be a list"},
+		{"pattern not a string", `{"pattern": 1}`, "pattern must be a string"},
+		{"invalid pattern", `{"pattern": "("}`, "pattern: error parsing regexp"},
+		// Patterns use Go's RE2 syntax, which has no lookaround or
+		// backreferences
+		{"lookahead pattern", `{"pattern": "^(?=a)"}`, "pattern: error parsing regexp"},
+		{"backreference pattern", `{"pattern": "(a)\\1"}`, "pattern: error parsing regexp"},
+		{"negative count", `{"minLength": -1}`, "minLength must be a non-negative integer"},
+		{"fractional count", `{"maxItems": 1.5}`, "maxItems must be a non-negative integer"},
+		{"boolean exclusiveMinimum", `{"exclusiveMinimum": true}`, "exclusiveMinimum must be a number"},
+		{"properties not an object", `{"properties": []}`, "properties must be an object"},
+		{"nested error path", `{"properties": {"a": {"items": {"type": "x"}}}}`, `properties.a.items.type: unknown type "x"`},
+		{"property schema not an object", `{"properties": {"a": 1}}`, "properties.a must be an object"},
+		{"required not a list", `{"required": "a"}`, "required must be a list"},
+		{"required of non-strings", `{"required": [1]}`, "required must list property names"},
+		{"additionalProperties not a schema", `{"additionalProperties": 1}`, "additionalProperties must be an object"},
+		{"empty oneOf", `{"oneOf": []}`, "oneOf must be a non-empty list"},
+		{"anyOf entry", `{"anyOf": [{}, 1]}`, "anyOf[1] must be an object"},
+		{"not not a schema", `{"not": true}`, "not must be an object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.schema), &doc); err != nil {
+				t.Fatalf("bad test schema: %v", err)
+			}
+			_, err := Compile(doc)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compile(%s) error = %v, want it to contain %q", tt.schema, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileYAMLNumbers(t *testing.T) {
+	// YAML decoders produce ints and nested map types
+	s, err := Compile(map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"n": map[string]interface{}{"type": "integer", "maximum": 10}},
+		"required":   []interface{}{"n"},
+	})
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if err := s.Validate(map[string]interface{}{"n": float64(11)}); err == nil {
+		t.Error("maximum given as a YAML int was not applied")
+	}
+}
+
+func TestErrorsPrefix(t *testing.T) {
+	errs := Errors{{Path: "", Message: "must be an object"}, {Path: "a[0]", Message: "is required"}}
+	got := errs.Prefix("payload").Error()
+	want := "payload: must be an object; payload.a[0]: is required"
+	if got != want {
+		t.Errorf("Prefix = %q, want %q", got, want)
+	}
+}
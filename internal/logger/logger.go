@@ -22,6 +22,21 @@ func Init(level string) {
 	}
 }
 
+// SetLevel changes the level of the running logger. An empty level means info.
+func SetLevel(level string) error {
+	if level == "" {
+		level = "info"
+	}
+
+	lvl, err := logrus.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return err
+	}
+
+	GetLogger().SetLevel(lvl)
+	return nil
+}
+
 func GetLogger() *logrus.Logger {
 	if log == nil {
 		Init("info") // Initialize with default info level if not already initialized
@@ -0,0 +1,57 @@
+package master
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupBlobRoutes(r *gin.RouterGroup, blobs BlobStore) {
+	// Upload blob endpoint; the raw request body is stored under its digest
+	r.PUT("/blobs", func(c *gin.Context) {
+		blob, created, err := blobs.Put(c.Request.Body)
+		if err != nil {
+			respondError(c, CodeInternal, fmt.Errorf("failed to store blob: %w", err))
+			return
+		}
+
+		if !created {
+			c.JSON(http.StatusOK, blob)
+			return
+		}
+		logger.GetLogger().Infof("Stored blob %s (%d bytes)", blob.Digest, blob.Size)
+		c.JSON(http.StatusCreated, blob)
+	})
+
+	// Blob download endpoint; blobs never change, so they can be cached
+	// forever by digest
+	serveBlob := func(c *gin.Context) {
+		content, blob, err := blobs.Open(c.Param("digest"))
+		if err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+		defer content.Close()
+
+		c.Header("ETag", `"`+blob.Digest+`"`)
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.Header("Content-Type", ContentTypeBinary)
+		http.ServeContent(c.Writer, c.Request, "", blob.CreatedAt, content)
+	}
+	r.GET("/blobs/:digest", serveBlob)
+	r.HEAD("/blobs/:digest", serveBlob)
+
+	// Delete blob endpoint
+	r.DELETE("/blobs/:digest", func(c *gin.Context) {
+		if err := blobs.Delete(c.Param("digest")); err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+
+		logger.GetLogger().Infof("Deleted blob %s", c.Param("digest"))
+		c.Status(http.StatusNoContent)
+	})
+}
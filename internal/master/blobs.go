@@ -0,0 +1,186 @@
+package master
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBlobNotFound  = errors.New("blob not found")
+	ErrInvalidDigest = errors.New("invalid blob digest")
+)
+
+// BlobRefPrefix marks a payload that refers to a blob instead of carrying
+// the data, e.g. blob://sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae.
+const BlobRefPrefix = "blob://"
+
+const digestAlgorithm = "sha256:"
+
+// Blob describes a stored blob. Digest is the SHA-256 of its content, as
+// sha256:<hex>.
+type Blob struct {
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Ref       string    `json:"ref"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BlobStore keeps content-addressed blobs, so storing the same content twice
+// keeps a single copy.
+type BlobStore interface {
+	// Put stores the content read from r and reports whether it was new.
+	Put(r io.Reader) (*Blob, bool, error)
+	Stat(digest string) (*Blob, error)
+	Open(digest string) (io.ReadSeekCloser, *Blob, error)
+	Delete(digest string) error
+}
+
+// ParseDigest checks a digest of the form sha256:<hex> and returns its hex
+// part.
+func ParseDigest(digest string) (string, error) {
+	sum, ok := strings.CutPrefix(digest, digestAlgorithm)
+	if !ok {
+		return "", fmt.Errorf("%w %q: must start with %s", ErrInvalidDigest, digest, digestAlgorithm)
+	}
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 || strings.ToLower(sum) != sum {
+		return "", fmt.Errorf("%w %q: must be 64 lowercase hex digits", ErrInvalidDigest, digest)
+	}
+	return sum, nil
+}
+
+// blobRef returns the digest of a payload that refers to a blob, given as
+// the text blob://sha256:<hex>, and false for any other payload.
+func (r *TaskRequest) blobRef() (string, bool, error) {
+	ref := string(r.data)
+	if len(r.data) == 0 {
+		if err := json.Unmarshal(r.Payload, &ref); err != nil {
+			return "", false, nil
+		}
+	}
+
+	digest, ok := strings.CutPrefix(ref, BlobRefPrefix)
+	if !ok {
+		return "", false, nil
+	}
+	if _, err := ParseDigest(digest); err != nil {
+		return "", true, err
+	}
+	return digest, true, nil
+}
+
+// FileBlobStore keeps blobs as files named by their digest in a directory.
+type FileBlobStore struct {
+	dir string
+}
+
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+// Put writes the content to a temporary file while hashing it, then moves
+// it to its digest's name.
+func (s *FileBlobStore) Put(r io.Reader) (*Blob, bool, error) {
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to store blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to store blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	digest := digestAlgorithm + hex.EncodeToString(hash.Sum(nil))
+	if blob, err := s.Stat(digest); err == nil {
+		return blob, false, nil
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(digest)); err != nil {
+		return nil, false, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	blob, err := s.Stat(digest)
+	if err != nil {
+		return nil, false, err
+	}
+	if size != blob.Size {
+		return nil, false, fmt.Errorf("failed to store blob: wrote %d bytes, stored %d", size, blob.Size)
+	}
+	return blob, true, nil
+}
+
+func (s *FileBlobStore) Stat(digest string) (*Blob, error) {
+	if _, err := ParseDigest(digest); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(s.path(digest))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, digest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(digest, info), nil
+}
+
+func (s *FileBlobStore) Open(digest string) (io.ReadSeekCloser, *Blob, error) {
+	blob, err := s.Stat(digest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(s.path(digest))
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, blob, nil
+}
+
+func (s *FileBlobStore) Delete(digest string) error {
+	if _, err := s.Stat(digest); err != nil {
+		return err
+	}
+	return os.Remove(s.path(digest))
+}
+
+// path is the file of a digest that has been checked with ParseDigest.
+func (s *FileBlobStore) path(digest string) string {
+	return filepath.Join(s.dir, strings.TrimPrefix(digest, digestAlgorithm))
+}
+
+func newBlob(digest string, info os.FileInfo) *Blob {
+	return &Blob{
+		Digest:    digest,
+		Size:      info.Size(),
+		Ref:       BlobRefPrefix + digest,
+		CreatedAt: info.ModTime(),
+	}
+}
+
+// readBlob returns the whole content of a blob.
+func readBlob(store BlobStore, digest string) ([]byte, error) {
+	r, _, err := store.Open(digest)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
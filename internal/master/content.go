@@ -0,0 +1,133 @@
+package master
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/mediatype"
+)
+
+// Content types assumed when a payload comes without one.
+const (
+	ContentTypeText   = mediatype.Text
+	ContentTypeBinary = mediatype.Binary
+	ContentTypeJSON   = mediatype.JSON
+)
+
+// MetadataHeaderPrefix marks the HTTP headers that carry task metadata on raw
+// uploads and result downloads, e.g. X-Task-Metadata-Source: camera-1.
+const MetadataHeaderPrefix = "X-Task-Metadata-"
+
+// content resolves the request's payload into bytes and their content type.
+// Exactly one of the raw data, payload or payload_base64 must be given. A
+// payload that is a JSON string is text, and defaults to text/plain; any
+// other JSON value is carried as JSON and defaults to application/json. The
+// remaining payloads default to application/octet-stream. Payloads declared
+// as JSON must be valid JSON.
+func (r *TaskRequest) content() ([]byte, string, error) {
+	data, contentType, err := r.resolveContent()
+	if err != nil {
+		return nil, "", err
+	}
+	if mediatype.IsJSON(contentType) && !json.Valid(data) {
+		return nil, "", fmt.Errorf("payload is not valid JSON")
+	}
+	return data, contentType, nil
+}
+
+func (r *TaskRequest) resolveContent() ([]byte, string, error) {
+	if len(r.data) > 0 {
+		return r.data, contentTypeOr(r.ContentType, ContentTypeBinary), nil
+	}
+
+	hasPayload := len(r.Payload) > 0 && !bytes.Equal(r.Payload, []byte("null"))
+	switch {
+	case hasPayload && r.PayloadBase64 != "":
+		return nil, "", fmt.Errorf("only one of payload or payload_base64 may be set")
+	case r.PayloadBase64 != "":
+		data, err := base64.StdEncoding.DecodeString(r.PayloadBase64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid payload_base64: %w", err)
+		}
+		if len(data) == 0 {
+			return nil, "", fmt.Errorf("payload is required")
+		}
+		return data, contentTypeOr(r.ContentType, ContentTypeBinary), nil
+	case hasPayload:
+		var text string
+		if err := json.Unmarshal(r.Payload, &text); err == nil {
+			if text == "" {
+				return nil, "", fmt.Errorf("payload is required")
+			}
+			return []byte(text), contentTypeOr(r.ContentType, ContentTypeText), nil
+		}
+
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, r.Payload); err != nil {
+			return nil, "", fmt.Errorf("invalid payload: %w", err)
+		}
+		return compact.Bytes(), contentTypeOr(r.ContentType, ContentTypeJSON), nil
+	default:
+		return nil, "", fmt.Errorf("payload is required")
+	}
+}
+
+func contentTypeOr(contentType, fallback string) string {
+	if contentType == "" {
+		return fallback
+	}
+	return contentType
+}
+
+// encodeData renders bytes for JSON. JSON content is embedded as is, other
+// valid UTF-8 becomes a string, and anything else is returned as base64 in
+// the second return value instead.
+func encodeData(data []byte, contentType string) (json.RawMessage, string) {
+	if len(data) == 0 {
+		return nil, ""
+	}
+	if mediatype.IsJSON(contentType) && json.Valid(data) {
+		return data, ""
+	}
+	if utf8.Valid(data) {
+		text, _ := json.Marshal(string(data))
+		return text, ""
+	}
+	return nil, base64.StdEncoding.EncodeToString(data)
+}
+
+// describeData renders bytes for logs and summaries, which must stay
+// printable.
+func describeData(data []byte, contentType string) string {
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return fmt.Sprintf("<%d bytes of %s>", len(data), contentType)
+}
+
+// metadataFromHeader collects the X-Task-Metadata-* headers, with their keys
+// in lower case.
+func metadataFromHeader(header http.Header) map[string]string {
+	var metadata map[string]string
+	for key, values := range header {
+		if !strings.HasPrefix(key, MetadataHeaderPrefix) || len(values) == 0 {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[strings.ToLower(strings.TrimPrefix(key, MetadataHeaderPrefix))] = values[0]
+	}
+	return metadata
+}
+
+func setMetadataHeader(header http.Header, metadata map[string]string) {
+	for key, value := range metadata {
+		header.Set(MetadataHeaderPrefix+key, value)
+	}
+}
@@ -0,0 +1,86 @@
+package master
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParseCron(t *testing.T, expr string) *CronSchedule {
+	t.Helper()
+	c, err := ParseCron(expr)
+	if err != nil {
+		t.Fatalf("ParseCron(%q): %v", expr, err)
+	}
+	return c
+}
+
+func TestCronNext(t *testing.T) {
+	utc := time.UTC
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 1, 10, 0, 30, 0, utc), time.Date(2024, 1, 1, 10, 1, 0, 0, utc)},
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 1, 0, 0, utc), time.Date(2024, 1, 1, 10, 15, 0, 0, utc)},
+		{"0 11 * * *", time.Date(2024, 1, 1, 12, 0, 0, 0, utc), time.Date(2024, 1, 2, 11, 0, 0, 0, utc)},
+		{"30 2 1 * *", time.Date(2024, 1, 15, 0, 0, 0, 0, utc), time.Date(2024, 2, 1, 2, 30, 0, 0, utc)},
+		{"0 0 29 2 *", time.Date(2024, 3, 1, 0, 0, 0, 0, utc), time.Date(2028, 2, 29, 0, 0, 0, 0, utc)},
+		{"0 9-17/4 * * 1-5", time.Date(2024, 1, 5, 17, 0, 0, 0, utc), time.Date(2024, 1, 8, 9, 0, 0, 0, utc)},
+		{"0 0 * * 7", time.Date(2024, 1, 1, 0, 0, 0, 0, utc), time.Date(2024, 1, 7, 0, 0, 0, 0, utc)},
+		{"@hourly", time.Date(2024, 1, 1, 10, 0, 0, 0, utc), time.Date(2024, 1, 1, 11, 0, 0, 0, utc)},
+		{"@yearly", time.Date(2024, 6, 1, 0, 0, 0, 0, utc), time.Date(2025, 1, 1, 0, 0, 0, 0, utc)},
+		// Both day fields restricted: either one matches
+		{"0 0 13 * 5", time.Date(2024, 1, 1, 0, 0, 0, 0, utc), time.Date(2024, 1, 5, 0, 0, 0, 0, utc)},
+		{"0 0 30 2 *", time.Date(2024, 1, 1, 0, 0, 0, 0, utc), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		got := mustParseCron(t, tt.expr).Next(tt.after)
+		if !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%v) = %v, want %v", tt.expr, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextInHalfHourZone(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	c := mustParseCron(t, "0 11 * * *")
+
+	got := c.Next(time.Date(2024, 1, 1, 9, 10, 0, 0, ist))
+	want := time.Date(2024, 1, 1, 11, 0, 0, 0, ist)
+	if !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * *", "must have 5 fields"},
+		{"60 * * * *", "minute field \"60\" out of range 0-59"},
+		{"* * * * 8", "day of week field \"8\" out of range 0-7"},
+		{"* * 0 * *", "day of month field \"0\" out of range 1-31"},
+		{"*/0 * * * *", "invalid step"},
+		{"5-1 * * * *", "out of range"},
+		{"a * * * *", "invalid value"},
+		{"1-b * * * *", "invalid range"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCron(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseCron(%q) error = %v, want it to contain %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestIntervalScheduleNext(t *testing.T) {
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := (intervalSchedule{every: time.Minute}).Next(after); !got.Equal(after.Add(time.Minute)) {
+		t.Errorf("Next = %v", got)
+	}
+}
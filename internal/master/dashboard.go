@@ -0,0 +1,24 @@
+package master
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardFiles is the web dashboard, a single page that only talks to the
+// API.
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// setupDashboard serves the dashboard under /dashboard/.
+func setupDashboard(r *gin.Engine) {
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+	r.StaticFS("/dashboard", http.FS(files))
+}
@@ -0,0 +1,277 @@
+// The dashboard polls the workers, loads the recent tasks and follows the
+// task event stream; it only uses the master's public API.
+(function () {
+  'use strict';
+
+  // Relative, so the dashboard works behind a proxy that mounts the master
+  // under a path
+  const api = '../v1';
+
+  const workerInterval = 5000;
+  const bucketSeconds = 5;
+  const bucketCount = 60;
+  const maxTasks = 100;
+  const shownTasks = 50;
+
+  // tasks holds the latest state of every task seen, by ID
+  const tasks = new Map();
+  // finished records when tasks finished and whether they succeeded
+  let finished = [];
+
+  function isFinished(state) {
+    return state === 'completed' || state === 'failed' || state === 'cancelled' || state === 'timed_out';
+  }
+
+  function el(tag, attrs, ...children) {
+    const node = document.createElement(tag);
+    for (const [key, value] of Object.entries(attrs || {})) {
+      if (key === 'onclick') {
+        node.onclick = value;
+      } else {
+        node.setAttribute(key, value);
+      }
+    }
+    for (const child of children) {
+      node.append(child);
+    }
+    return node;
+  }
+
+  function badge(text, kind) {
+    return el('span', { class: 'badge ' + (kind || text) }, text);
+  }
+
+  function showMessage(text) {
+    const message = document.getElementById('message');
+    message.textContent = text;
+    message.hidden = !text;
+  }
+
+  async function call(method, path) {
+    const resp = await fetch(api + path, { method: method });
+    const body = await resp.json().catch(() => ({}));
+    if (!resp.ok) {
+      throw new Error(body.error || resp.status + ' ' + resp.statusText);
+    }
+    return body;
+  }
+
+  // Workers
+
+  async function refreshWorkers() {
+    try {
+      const [list, status] = await Promise.all([call('GET', '/workers'), call('GET', '/status')]);
+      renderWorkers(list.workers || [], status.workers || {});
+    } catch (err) {
+      showMessage('Failed to load workers: ' + err.message);
+    }
+  }
+
+  function renderWorkers(workers, statuses) {
+    const rows = workers.map((worker) => {
+      const status = statuses[worker.id];
+      let state = status ? badge(status.status) : badge('unreachable');
+      if (worker.cordoned) {
+        state = badge('draining');
+      }
+
+      const action = worker.cordoned ? 'uncordon' : 'cordon';
+      const button = el('button', { onclick: () => drain(worker.id, action) }, worker.cordoned ? 'Resume' : 'Drain');
+
+      const labels = Object.entries(worker.labels || {}).map(([key, value]) => key + '=' + value).join(', ');
+      return el('tr', {},
+        el('td', {}, el('code', {}, worker.id)),
+        el('td', {}, worker.url),
+        el('td', {}, state),
+        el('td', {}, status ? String(status.active_tasks) : '-'),
+        el('td', {}, (worker.task_types || []).join(', ') || 'any'),
+        el('td', {}, labels || '-'),
+        el('td', {}, button));
+    });
+    document.getElementById('workers').replaceChildren(...rows);
+  }
+
+  async function drain(workerID, action) {
+    try {
+      await call('POST', '/workers/' + encodeURIComponent(workerID) + '/' + action);
+      showMessage('');
+      refreshWorkers();
+    } catch (err) {
+      showMessage('Failed to ' + action + ' ' + workerID + ': ' + err.message);
+    }
+  }
+
+  // Tasks
+
+  // loadTasks seeds the list with the tasks the master already has. Tasks
+  // that events updated meanwhile keep their newer state.
+  async function loadTasks() {
+    let list;
+    try {
+      list = await call('GET', '/tasks?limit=' + maxTasks);
+    } catch (err) {
+      showMessage('Failed to load tasks: ' + err.message);
+      return;
+    }
+
+    const live = Array.from(tasks.values());
+    tasks.clear();
+    // The newest task comes first, and the map is ordered oldest first
+    for (const task of (list.tasks || []).reverse()) {
+      if (live.some((other) => other.task_id === task.task_id)) {
+        continue;
+      }
+      if (isFinished(task.state) && task.finished_at) {
+        finished.push({ at: Date.parse(task.finished_at), success: task.success });
+      }
+      tasks.set(task.task_id, task);
+    }
+    for (const task of live) {
+      tasks.set(task.task_id, task);
+    }
+    while (tasks.size > maxTasks) {
+      tasks.delete(tasks.keys().next().value);
+    }
+    renderTasks();
+  }
+
+  function followEvents() {
+    const connection = document.getElementById('connection');
+    const events = new EventSource(api + '/events');
+    events.onopen = () => {
+      connection.replaceWith(Object.assign(badge('live'), { id: 'connection' }));
+    };
+    events.onerror = () => {
+      document.getElementById('connection').replaceWith(Object.assign(badge('offline'), { id: 'connection' }));
+    };
+    events.addEventListener('task', (event) => onTask(JSON.parse(event.data)));
+  }
+
+  function onTask(task) {
+    const previous = tasks.get(task.task_id);
+    if (isFinished(task.state) && !(previous && isFinished(previous.state))) {
+      finished.push({ at: Date.now(), success: task.success });
+    }
+
+    // Re-inserting keeps the map ordered by the last update
+    tasks.delete(task.task_id);
+    tasks.set(task.task_id, task);
+    while (tasks.size > maxTasks) {
+      tasks.delete(tasks.keys().next().value);
+    }
+    renderTasks();
+  }
+
+  function duration(task) {
+    if (!task.started_at) {
+      return '-';
+    }
+    const end = task.finished_at ? new Date(task.finished_at) : new Date();
+    const ms = end - new Date(task.started_at);
+    return ms < 1000 ? ms + 'ms' : (ms / 1000).toFixed(1) + 's';
+  }
+
+  function outcome(task) {
+    if (task.error) {
+      return task.error_code ? task.error_code + ': ' + task.error : task.error;
+    }
+    if (task.result_base64) {
+      return '<' + atob(task.result_base64).length + ' bytes of ' + task.result_content_type + '>';
+    }
+    if (task.result === undefined) {
+      return '';
+    }
+    return typeof task.result === 'string' ? task.result : JSON.stringify(task.result);
+  }
+
+  function renderTasks() {
+    const recent = Array.from(tasks.values()).reverse().slice(0, shownTasks);
+    const rows = recent.map((task) => {
+      const worker = (task.result_metadata || {}).worker_id || '-';
+      const text = outcome(task);
+      const actions = el('td', {});
+      if (!isFinished(task.state)) {
+        actions.append(el('button', { onclick: () => cancel(task.task_id) }, 'Cancel'));
+      }
+      return el('tr', {},
+        el('td', { title: task.task_id }, el('code', {}, task.task_id.slice(0, 8))),
+        el('td', {}, task.task_type),
+        el('td', {}, badge(task.state), task.cached ? ' cached' : ''),
+        el('td', {}, worker),
+        el('td', {}, duration(task)),
+        el('td', { class: 'result', title: text }, text),
+        actions);
+    });
+    document.getElementById('tasks').replaceChildren(...rows);
+  }
+
+  async function cancel(taskID) {
+    try {
+      onTask(await call('POST', '/tasks/' + encodeURIComponent(taskID) + '/cancel'));
+      showMessage('');
+    } catch (err) {
+      showMessage('Failed to cancel task ' + taskID + ': ' + err.message);
+    }
+  }
+
+  // Throughput
+
+  function drawThroughput() {
+    const now = Date.now();
+    const span = bucketSeconds * bucketCount * 1000;
+    finished = finished.filter((entry) => now - entry.at < span);
+
+    const succeeded = new Array(bucketCount).fill(0);
+    const failed = new Array(bucketCount).fill(0);
+    for (const entry of finished) {
+      const bucket = bucketCount - 1 - Math.floor((now - entry.at) / (bucketSeconds * 1000));
+      (entry.success ? succeeded : failed)[bucket]++;
+    }
+
+    const canvas = document.getElementById('throughput');
+    const ctx = canvas.getContext('2d');
+    const top = 16;
+    const bottom = canvas.height - 16;
+    const width = canvas.width / bucketCount;
+    const max = Math.max(1, ...succeeded.map((n, i) => n + failed[i]));
+    const scale = (bottom - top) / max;
+
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+    ctx.fillStyle = '#57606a';
+    ctx.font = '12px sans-serif';
+    ctx.fillText(max + ' tasks', 4, 12);
+    ctx.fillText('5 min ago', 4, canvas.height - 2);
+    ctx.fillText('now', canvas.width - 28, canvas.height - 2);
+    ctx.strokeStyle = '#d0d7de';
+    ctx.beginPath();
+    ctx.moveTo(0, bottom + 0.5);
+    ctx.lineTo(canvas.width, bottom + 0.5);
+    ctx.stroke();
+
+    for (let i = 0; i < bucketCount; i++) {
+      const x = i * width + 1;
+      const ok = succeeded[i] * scale;
+      const bad = failed[i] * scale;
+      ctx.fillStyle = '#2da44e';
+      ctx.fillRect(x, bottom - ok, width - 2, ok);
+      ctx.fillStyle = '#cf222e';
+      ctx.fillRect(x, bottom - ok - bad, width - 2, bad);
+    }
+
+    const lastMinute = finished.filter((entry) => now - entry.at < 60000);
+    const failures = lastMinute.filter((entry) => !entry.success).length;
+    document.getElementById('rates').textContent = 'Last minute: ' + (lastMinute.length / 60).toFixed(2) +
+      ' tasks/s, ' + failures + ' failed. Green bars succeeded, red ones failed.';
+  }
+
+  refreshWorkers();
+  setInterval(refreshWorkers, workerInterval);
+  // Following events first means no update is missed while loading
+  followEvents();
+  loadTasks();
+  drawThroughput();
+  setInterval(() => {
+    drawThroughput();
+    renderTasks();
+  }, 1000);
+})();
@@ -0,0 +1,147 @@
+package master
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+type delayedTask struct {
+	task  *Task
+	runAt time.Time
+	index int
+}
+
+// delayHeap is a min-heap of delayed tasks ordered by their due time.
+type delayHeap []*delayedTask
+
+func (h delayHeap) Len() int           { return len(h) }
+func (h delayHeap) Less(i, j int) bool { return h[i].runAt.Before(h[j].runAt) }
+
+func (h delayHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *delayHeap) Push(x any) {
+	item := x.(*delayedTask)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *delayHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// DelayQueue holds tasks until their run time and then hands them to the
+// dispatch function, each on its own goroutine.
+type DelayQueue struct {
+	mu       sync.Mutex
+	items    delayHeap
+	dispatch func(*Task)
+
+	wake chan struct{}
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewDelayQueue(dispatch func(*Task)) *DelayQueue {
+	q := &DelayQueue{
+		dispatch: dispatch,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+
+	q.wg.Add(1)
+	go q.run()
+
+	return q
+}
+
+// Push queues a task to be dispatched at runAt.
+func (q *DelayQueue) Push(task *Task, runAt time.Time) {
+	q.mu.Lock()
+	heap.Push(&q.items, &delayedTask{task: task, runAt: runAt})
+	q.mu.Unlock()
+
+	// Wake the loop in case the new task is due before the current earliest
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Remove drops a task that has not been dispatched yet and reports whether
+// it was still queued.
+func (q *DelayQueue) Remove(task *Task) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, item := range q.items {
+		if item.task == task {
+			heap.Remove(&q.items, item.index)
+			return true
+		}
+	}
+	return false
+}
+
+func (q *DelayQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Close stops the queue and waits for dispatched tasks to finish. Tasks that
+// are still waiting are dropped.
+func (q *DelayQueue) Close() {
+	close(q.stop)
+	q.wg.Wait()
+}
+
+func (q *DelayQueue) run() {
+	defer q.wg.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		q.mu.Lock()
+		now := time.Now()
+		for len(q.items) > 0 && !q.items[0].runAt.After(now) {
+			item := heap.Pop(&q.items).(*delayedTask)
+			q.wg.Add(1)
+			go func() {
+				defer q.wg.Done()
+				q.dispatch(item.task)
+			}()
+		}
+
+		wait := time.Hour
+		if len(q.items) > 0 {
+			wait = q.items[0].runAt.Sub(now)
+		}
+		q.mu.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+		case <-q.wake:
+		case <-q.stop:
+			return
+		}
+	}
+}
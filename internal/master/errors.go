@@ -0,0 +1,166 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/jsonschema"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorCode classifies an error response, and why a task failed.
+type ErrorCode string
+
+const (
+	CodeInvalidArgument  ErrorCode = "invalid_argument"
+	CodeNotFound         ErrorCode = "not_found"
+	CodeConflict         ErrorCode = "conflict"
+	CodeUnknownTaskType  ErrorCode = "unknown_task_type"
+	CodeNoMatchingWorker ErrorCode = "no_matching_worker"
+	CodeUnavailable      ErrorCode = "unavailable"
+	CodeDeadlineExceeded ErrorCode = "deadline_exceeded"
+	CodeWorkerError      ErrorCode = "worker_error"
+	CodeCancelled        ErrorCode = "cancelled"
+	CodeInternal         ErrorCode = "internal"
+
+	// Tasks that ran but failed carry one of these; they are outcomes of
+	// the task rather than errors of the request
+	CodeTaskFailed     ErrorCode = "task_failed"
+	CodeInvalidPayload ErrorCode = "invalid_payload"
+	CodeInvalidResult  ErrorCode = "invalid_result"
+)
+
+var codeStatuses = map[ErrorCode]int{
+	CodeInvalidArgument:  http.StatusBadRequest,
+	CodeNotFound:         http.StatusNotFound,
+	CodeConflict:         http.StatusConflict,
+	CodeUnknownTaskType:  http.StatusUnprocessableEntity,
+	CodeNoMatchingWorker: http.StatusUnprocessableEntity,
+	CodeUnavailable:      http.StatusServiceUnavailable,
+	CodeDeadlineExceeded: http.StatusGatewayTimeout,
+	CodeWorkerError:      http.StatusBadGateway,
+	CodeCancelled:        http.StatusConflict,
+	CodeInternal:         http.StatusInternalServerError,
+	CodeTaskFailed:       http.StatusOK,
+	CodeInvalidPayload:   http.StatusOK,
+	CodeInvalidResult:    http.StatusOK,
+}
+
+// HTTPStatus is the status of a response carrying the code.
+func (c ErrorCode) HTTPStatus() int {
+	if status, ok := codeStatuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+var codeGRPCCodes = map[ErrorCode]codes.Code{
+	CodeInvalidArgument:  codes.InvalidArgument,
+	CodeNotFound:         codes.NotFound,
+	CodeConflict:         codes.FailedPrecondition,
+	CodeUnknownTaskType:  codes.FailedPrecondition,
+	CodeNoMatchingWorker: codes.FailedPrecondition,
+	CodeUnavailable:      codes.Unavailable,
+	CodeDeadlineExceeded: codes.DeadlineExceeded,
+	CodeWorkerError:      codes.Unavailable,
+	CodeCancelled:        codes.Canceled,
+	CodeInternal:         codes.Internal,
+}
+
+// GRPCCode is the gRPC status code of an error carrying the code.
+func (c ErrorCode) GRPCCode() codes.Code {
+	if code, ok := codeGRPCCodes[c]; ok {
+		return code
+	}
+	return codes.Internal
+}
+
+// ErrorResponse is the body of every error response. Details lists the
+// fields of a request body that break the API's schema, or of a payload that
+// breaks its task type's.
+type ErrorResponse struct {
+	Error   string            `json:"error"`
+	Code    ErrorCode         `json:"code"`
+	Details jsonschema.Errors `json:"details,omitempty"`
+}
+
+// errorCode classifies an error from the task manager, the worker pool or a
+// worker.
+func errorCode(err error) ErrorCode {
+	switch {
+	case errors.Is(err, ErrInvalidTask), errors.Is(err, ErrInvalidDigest):
+		return CodeInvalidArgument
+	case errors.Is(err, ErrTaskNotFound), errors.Is(err, ErrWorkerNotFound),
+		errors.Is(err, ErrScheduleNotFound), errors.Is(err, ErrBlobNotFound):
+		return CodeNotFound
+	case errors.Is(err, ErrTaskFinished), errors.Is(err, ErrIdempotencyKeyReused),
+		errors.Is(err, ErrWorkerExists), errors.Is(err, ErrLastWorker):
+		return CodeConflict
+	case errors.Is(err, ErrNoCapableWorker):
+		return CodeUnknownTaskType
+	case errors.Is(err, ErrNoMatchingWorker):
+		return CodeNoMatchingWorker
+	case errors.Is(err, ErrNoWorkers), errors.Is(err, ErrWorkersCordoned):
+		return CodeUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	}
+
+	// Anything else that carries a gRPC status came from a worker
+	s, ok := status.FromError(err)
+	if !ok {
+		return CodeInternal
+	}
+	switch s.Code() {
+	case codes.DeadlineExceeded:
+		return CodeDeadlineExceeded
+	case codes.Unavailable:
+		return CodeUnavailable
+	case codes.Canceled:
+		return CodeCancelled
+	default:
+		return CodeWorkerError
+	}
+}
+
+// respondError writes an error response with the status its code maps to.
+// Internal errors are logged, since nothing else reports them.
+func respondError(c *gin.Context, code ErrorCode, err error) {
+	if code == CodeInternal {
+		logger.GetLogger().Errorf("%s %s failed: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+
+	resp := ErrorResponse{Error: err.Error(), Code: code}
+	var schemaErr *SchemaError
+	var fieldErrs jsonschema.Errors
+	switch {
+	case errors.As(err, &schemaErr):
+		resp.Details = schemaErr.Errors
+	case errors.As(err, &fieldErrs):
+		resp.Details = fieldErrs
+	}
+	c.JSON(code.HTTPStatus(), resp)
+}
+
+// workerErrorCodes maps the reasons workers give for failing a task.
+var workerErrorCodes = map[pb.ErrorCode]ErrorCode{
+	pb.ErrorCode_ERROR_CODE_TASK_FAILED:           CodeTaskFailed,
+	pb.ErrorCode_ERROR_CODE_INVALID_PAYLOAD:       CodeInvalidPayload,
+	pb.ErrorCode_ERROR_CODE_UNSUPPORTED_TASK_TYPE: CodeWorkerError,
+	pb.ErrorCode_ERROR_CODE_PAYLOAD_UNAVAILABLE:   CodeWorkerError,
+}
+
+func workerErrorCode(code pb.ErrorCode) ErrorCode {
+	if code, ok := workerErrorCodes[code]; ok {
+		return code
+	}
+	return CodeTaskFailed
+}
@@ -0,0 +1,50 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestErrorClassification checks that REST and gRPC report each error the
+// same way, and that errors from workers keep their gRPC status code.
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		code       ErrorCode
+		httpStatus int
+		grpcCode   codes.Code
+	}{
+		{"invalid task", fmt.Errorf("%w: task_type is required", ErrInvalidTask), CodeInvalidArgument, http.StatusBadRequest, codes.InvalidArgument},
+		{"unknown task", ErrTaskNotFound, CodeNotFound, http.StatusNotFound, codes.NotFound},
+		{"reused idempotency key", ErrIdempotencyKeyReused, CodeConflict, http.StatusConflict, codes.FailedPrecondition},
+		{"finished task", ErrTaskFinished, CodeConflict, http.StatusConflict, codes.FailedPrecondition},
+		{"unknown task type", fmt.Errorf("%w: image", ErrNoCapableWorker), CodeUnknownTaskType, http.StatusUnprocessableEntity, codes.FailedPrecondition},
+		{"no matching worker", ErrNoMatchingWorker, CodeNoMatchingWorker, http.StatusUnprocessableEntity, codes.FailedPrecondition},
+		{"cordoned", ErrWorkersCordoned, CodeUnavailable, http.StatusServiceUnavailable, codes.Unavailable},
+		{"deadline", context.DeadlineExceeded, CodeDeadlineExceeded, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{"worker unavailable", fmt.Errorf("calling worker-1: %w", status.Error(codes.Unavailable, "connection refused")), CodeUnavailable, http.StatusServiceUnavailable, codes.Unavailable},
+		{"worker deadline", status.Error(codes.DeadlineExceeded, "deadline exceeded"), CodeDeadlineExceeded, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{"worker error", fmt.Errorf("calling worker-1: %w", status.Error(codes.DataLoss, "checksum mismatch")), CodeWorkerError, http.StatusBadGateway, codes.DataLoss},
+		{"internal", fmt.Errorf("something broke"), CodeInternal, http.StatusInternalServerError, codes.Internal},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := errorCode(test.err)
+			if code != test.code {
+				t.Fatalf("errorCode = %s, want %s", code, test.code)
+			}
+			if got := code.HTTPStatus(); got != test.httpStatus {
+				t.Errorf("HTTP status = %d, want %d", got, test.httpStatus)
+			}
+			if got := status.Code(taskStatusError(test.err)); got != test.grpcCode {
+				t.Errorf("gRPC code = %s, want %s", got, test.grpcCode)
+			}
+		})
+	}
+}
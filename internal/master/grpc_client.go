@@ -2,7 +2,9 @@ package master
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -11,52 +13,264 @@ import (
 	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// ErrNoWorkers is returned when the pool has no workers at all.
+var ErrNoWorkers = errors.New("no workers available")
+
+// ErrNoCapableWorker is returned when no connected worker supports the
+// requested task type.
+var ErrNoCapableWorker = errors.New("no worker supports the task type")
+
+// ErrNoMatchingWorker is returned when no worker that supports the task type
+// satisfies the task's label selector.
+var ErrNoMatchingWorker = errors.New("no worker matches the selector")
+
+// ErrWorkersCordoned is returned when every worker that could run a task is
+// cordoned.
+var ErrWorkersCordoned = errors.New("every capable worker is cordoned")
+
+var (
+	ErrWorkerNotFound = errors.New("worker not found")
+	ErrWorkerExists   = errors.New("worker already exists")
+	ErrLastWorker     = errors.New("cannot remove the last worker")
+)
+
 type WorkerClient struct {
-	conn    *grpc.ClientConn
-	client  pb.WorkerServiceClient
-	addr    string
-	id      string
-	timeout time.Duration
+	conn   *grpc.ClientConn
+	client pb.WorkerServiceClient
+	addr   string
+	id     string
+
+	// inflight counts tasks dispatched to the worker so a removed worker's
+	// connection is only closed once they have finished
+	inflight sync.WaitGroup
+
+	// Capabilities come from the config when set there, otherwise from what
+	// the worker advertises in its status responses.
+	mu               sync.RWMutex
+	timeout          time.Duration
+	taskTypes        []string
+	labels           map[string]string
+	configuredTypes  bool
+	configuredLabels bool
+	// cordoned workers stay connected but receive no new tasks
+	cordoned bool
+}
+
+// transportCredentials returns TLS credentials when grpc.tls is configured
+// and plaintext ones otherwise.
+func transportCredentials(cfg *config.Config) (credentials.TransportCredentials, error) {
+	if !cfg.GRPC.TLS.Enabled() {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := cfg.GRPC.TLS.ClientTLS()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func dialWorker(workerConfig config.WorkerConfig, timeout time.Duration, creds credentials.TransportCredentials, dialOpts []grpc.DialOption) (*WorkerClient, error) {
+	logger.GetLogger().Infof("Connecting to worker %s at %s", workerConfig.ID, workerConfig.URL)
+
+	opts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithTimeout(timeout),
+	}, dialOpts...)
+
+	conn, err := grpc.Dial(workerConfig.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewWorkerServiceClient(conn)
+	worker := &WorkerClient{
+		conn:    conn,
+		client:  client,
+		addr:    workerConfig.URL,
+		id:      workerConfig.ID,
+		timeout: timeout,
+	}
+	worker.configure(workerConfig)
+
+	// Learn the worker's advertised capabilities up front; they are
+	// refreshed on every later status call
+	if _, err := worker.getStatus(); err != nil {
+		logger.GetLogger().Warnf("Failed to get capabilities of worker %s: %v", workerConfig.ID, err)
+	}
+
+	return worker, nil
+}
+
+// configure applies the task types and labels pinned in the config.
+func (w *WorkerClient) configure(workerConfig config.WorkerConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.configuredTypes = len(workerConfig.TaskTypes) > 0
+	if w.configuredTypes {
+		w.taskTypes = workerConfig.TaskTypes
+	}
+	w.configuredLabels = len(workerConfig.Labels) > 0
+	if w.configuredLabels {
+		w.labels = workerConfig.Labels
+	}
+}
+
+func (w *WorkerClient) Timeout() time.Duration {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.timeout
+}
+
+func (w *WorkerClient) setTimeout(timeout time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timeout = timeout
+}
+
+// closeWhenIdle closes the connection once every in-flight task finished.
+func (w *WorkerClient) closeWhenIdle() {
+	go func() {
+		w.inflight.Wait()
+		if w.conn != nil {
+			w.conn.Close()
+		}
+		logger.GetLogger().Infof("Disconnected from worker %s at %s", w.id, w.addr)
+	}()
+}
+
+// Supports reports whether the worker accepts the task type. Workers that
+// have not advertised any task types are assumed to accept all of them.
+func (w *WorkerClient) Supports(taskType string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(w.taskTypes) == 0 {
+		return true
+	}
+	for _, t := range w.taskTypes {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WorkerClient) Cordoned() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.cordoned
+}
+
+func (w *WorkerClient) setCordoned(cordoned bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cordoned = cordoned
+}
+
+// TaskTypes returns the task types the worker accepts; empty means all.
+func (w *WorkerClient) TaskTypes() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.taskTypes
+}
+
+func (w *WorkerClient) Labels() map[string]string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.labels
+}
+
+// updateCapabilities records the task types and labels advertised by the
+// worker, unless the config already pins them.
+func (w *WorkerClient) updateCapabilities(status *pb.StatusResponse) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.configuredTypes {
+		w.taskTypes = status.TaskTypes
+	}
+	if !w.configuredLabels {
+		w.labels = status.Labels
+	}
+}
+
+func (w *WorkerClient) getStatus() (*pb.StatusResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.Timeout())
+	defer cancel()
+
+	req := &pb.StatusRequest{
+		WorkerId: w.id,
+	}
+
+	status, err := w.client.GetStatus(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	w.updateCapabilities(status)
+	return status, nil
 }
 
 type WorkerPool struct {
+	mu      sync.RWMutex
 	workers []*WorkerClient
 	config  *config.Config
 	counter atomic.Int64
+	// creds are fixed at startup; TLS changes need a restart
+	creds credentials.TransportCredentials
+	// dialOpts are added to every worker connection
+	dialOpts []grpc.DialOption
+
+	// reconfigure serializes changes to the worker list, which dial workers
+	// without holding mu
+	reconfigure sync.Mutex
+	// persist, when set, saves the worker list after runtime changes
+	persist func([]config.WorkerConfig) error
+	// added holds the workers added at runtime that are not persisted, by
+	// ID, so reconciling with the file keeps them
+	added map[string]config.WorkerConfig
 }
 
-func NewWorkerPool(config *config.Config) (*WorkerPool, error) {
+// NewWorkerPool connects to the configured workers. dialOpts are used for
+// every worker connection, including ones added later, e.g. to dial workers
+// in-process.
+func NewWorkerPool(config *config.Config, dialOpts ...grpc.DialOption) (*WorkerPool, error) {
+	creds, err := transportCredentials(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up TLS: %w", err)
+	}
+
+	// Message size limits are fixed at startup too
+	dialOpts = append([]grpc.DialOption{
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(config.GetMaxMessageSize()),
+			grpc.MaxCallSendMsgSize(config.GetMaxMessageSize()),
+		),
+	}, dialOpts...)
+
 	pool := &WorkerPool{
-		workers: make([]*WorkerClient, 0, len(config.Workers)),
-		config:  config,
+		workers:  make([]*WorkerClient, 0, len(config.Workers)),
+		config:   config,
+		creds:    creds,
+		dialOpts: dialOpts,
 	}
 
 	timeout := config.GetGRPCTimeout()
 
 	for _, workerConfig := range config.Workers {
-		logger.GetLogger().Infof("Connecting to worker %s at %s", workerConfig.ID, workerConfig.URL)
-
-		conn, err := grpc.Dial(
-			workerConfig.URL,
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-			grpc.WithTimeout(timeout),
-		)
+		worker, err := dialWorker(workerConfig, timeout, creds, dialOpts)
 		if err != nil {
 			logger.GetLogger().Errorf("Failed to connect to worker %s at %s: %v", workerConfig.ID, workerConfig.URL, err)
 			continue
 		}
 
-		client := pb.NewWorkerServiceClient(conn)
-		pool.workers = append(pool.workers, &WorkerClient{
-			conn:    conn,
-			client:  client,
-			addr:    workerConfig.URL,
-			id:      workerConfig.ID,
-			timeout: timeout,
-		})
+		pool.workers = append(pool.workers, worker)
 	}
 
 	if len(pool.workers) == 0 {
@@ -66,62 +280,394 @@ func NewWorkerPool(config *config.Config) (*WorkerPool, error) {
 	return pool, nil
 }
 
-func (p *WorkerPool) ProcessTask(taskID, taskType, payload string) (*pb.TaskResponse, error) {
-	if len(p.workers) == 0 {
-		return nil, fmt.Errorf("no workers available")
+// Config returns the configuration the pool was last built or reconciled from.
+func (p *WorkerPool) Config() *config.Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config
+}
+
+// Reconcile brings the pool in line with a new configuration: workers no
+// longer listed are removed once their in-flight tasks finish, new ones are
+// connected, and the timeout and pinned capabilities of the rest are updated.
+// A worker whose URL changed is reconnected. Workers added through the admin
+// API are kept unless they are persisted, in which case the configuration
+// lists them, or the configuration now lists their ID itself.
+func (p *WorkerPool) Reconcile(cfg *config.Config) {
+	p.reconfigure.Lock()
+	defer p.reconfigure.Unlock()
+
+	timeout := cfg.GetGRPCTimeout()
+
+	listed := make(map[string]bool, len(cfg.Workers))
+	for _, workerConfig := range cfg.Workers {
+		listed[workerConfig.ID] = true
+	}
+	workerConfigs := append([]config.WorkerConfig(nil), cfg.Workers...)
+	for id, workerConfig := range p.added {
+		if listed[id] {
+			delete(p.added, id)
+			continue
+		}
+		workerConfigs = append(workerConfigs, workerConfig)
+	}
+	cfg = withWorkers(cfg, workerConfigs)
+
+	p.mu.Lock()
+	current := make(map[string]*WorkerClient, len(p.workers))
+	for _, worker := range p.workers {
+		current[worker.id] = worker
 	}
+	p.mu.Unlock()
 
-	// Simple round-robin selection
-	workerIdx := p.counter.Add(1) % int64(len(p.workers))
+	// Dial new workers before taking the lock so dispatch is not blocked
+	workers := make([]*WorkerClient, 0, len(cfg.Workers))
+	kept := make(map[string]bool, len(cfg.Workers))
+	for _, workerConfig := range cfg.Workers {
+		if worker, ok := current[workerConfig.ID]; ok && worker.addr == workerConfig.URL {
+			worker.setTimeout(timeout)
+			worker.configure(workerConfig)
+			workers = append(workers, worker)
+			kept[worker.id] = true
+			continue
+		}
 
-	// Optional: reset the counter when it wraps around
-	if workerIdx == 0 {
-		p.counter.Store(0)
+		worker, err := dialWorker(workerConfig, timeout, p.creds, p.dialOpts)
+		if err != nil {
+			logger.GetLogger().Errorf("Failed to connect to worker %s at %s: %v", workerConfig.ID, workerConfig.URL, err)
+			continue
+		}
+		logger.GetLogger().Infof("Added worker %s at %s", worker.id, worker.addr)
+		workers = append(workers, worker)
 	}
 
-	worker := p.workers[workerIdx]
+	p.mu.Lock()
+	p.workers = workers
+	p.config = cfg
+	p.mu.Unlock()
 
-	ctx, cancel := context.WithTimeout(context.Background(), worker.timeout)
-	defer cancel()
+	for id, worker := range current {
+		if !kept[id] {
+			logger.GetLogger().Infof("Removing worker %s at %s", worker.id, worker.addr)
+			worker.closeWhenIdle()
+		}
+	}
+}
 
-	req := &pb.TaskRequest{
-		TaskId:   taskID,
-		TaskType: taskType,
-		Payload:  payload,
+// SetPersist makes runtime changes to the worker list call save with the new
+// list. A change is rolled back when saving it fails.
+func (p *WorkerPool) SetPersist(save func([]config.WorkerConfig) error) {
+	p.reconfigure.Lock()
+	defer p.reconfigure.Unlock()
+	p.persist = save
+}
+
+// AddWorker connects to a new worker and puts it into rotation. Adding a
+// worker that is already in the pool with the same URL changes nothing and
+// reports false.
+func (p *WorkerPool) AddWorker(workerConfig config.WorkerConfig) (bool, error) {
+	p.reconfigure.Lock()
+	defer p.reconfigure.Unlock()
+
+	for _, worker := range p.snapshot() {
+		switch {
+		case worker.id == workerConfig.ID && worker.addr == workerConfig.URL:
+			return false, nil
+		case worker.id == workerConfig.ID:
+			return false, fmt.Errorf("%w: %s is at %s", ErrWorkerExists, worker.id, worker.addr)
+		case worker.addr == workerConfig.URL:
+			return false, fmt.Errorf("%w: %s is used by %s", ErrWorkerExists, worker.addr, worker.id)
+		}
 	}
 
-	return worker.client.ProcessTask(ctx, req)
+	cfg := p.Config()
+	worker, err := dialWorker(workerConfig, cfg.GetGRPCTimeout(), p.creds, p.dialOpts)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to worker %s at %s: %w", workerConfig.ID, workerConfig.URL, err)
+	}
+
+	workers := append(append([]config.WorkerConfig(nil), cfg.Workers...), workerConfig)
+	if err := p.save(workers); err != nil {
+		worker.closeWhenIdle()
+		return false, err
+	}
+
+	p.mu.Lock()
+	p.workers = append(p.workers, worker)
+	p.config = withWorkers(cfg, workers)
+	p.mu.Unlock()
+	if p.persist == nil {
+		if p.added == nil {
+			p.added = make(map[string]config.WorkerConfig)
+		}
+		p.added[workerConfig.ID] = workerConfig
+	}
+
+	logger.GetLogger().Infof("Added worker %s at %s", worker.id, worker.addr)
+	return true, nil
 }
 
-func (p *WorkerPool) GetWorkerStatus(workerID string) (*pb.StatusResponse, error) {
-	for _, worker := range p.workers {
+// RemoveWorker takes a worker out of the pool. Its connection is closed once
+// its in-flight tasks finish.
+func (p *WorkerPool) RemoveWorker(workerID string) error {
+	p.reconfigure.Lock()
+	defer p.reconfigure.Unlock()
+
+	cfg := p.Config()
+	current := p.snapshot()
+
+	var removed *WorkerClient
+	remaining := make([]*WorkerClient, 0, len(current))
+	for _, worker := range current {
 		if worker.id == workerID {
-			ctx, cancel := context.WithTimeout(context.Background(), worker.timeout)
-			defer cancel()
+			removed = worker
+			continue
+		}
+		remaining = append(remaining, worker)
+	}
+	if removed == nil {
+		return fmt.Errorf("%w: %s", ErrWorkerNotFound, workerID)
+	}
+	if len(remaining) == 0 {
+		return fmt.Errorf("%w; cordon it instead", ErrLastWorker)
+	}
+
+	workers := make([]config.WorkerConfig, 0, len(cfg.Workers))
+	for _, workerConfig := range cfg.Workers {
+		if workerConfig.ID != workerID {
+			workers = append(workers, workerConfig)
+		}
+	}
+	if err := p.save(workers); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.workers = remaining
+	p.config = withWorkers(cfg, workers)
+	p.mu.Unlock()
+	delete(p.added, workerID)
+
+	logger.GetLogger().Infof("Removing worker %s at %s", removed.id, removed.addr)
+	removed.closeWhenIdle()
+	return nil
+}
 
-			req := &pb.StatusRequest{
-				WorkerId: workerID,
-			}
+// Cordon stops or resumes sending new tasks to a worker. Cordoning is kept
+// across configuration reloads but is not persisted.
+func (p *WorkerPool) Cordon(workerID string, cordoned bool) error {
+	worker, err := p.worker(workerID)
+	if err != nil {
+		return err
+	}
 
-			return worker.client.GetStatus(ctx, req)
+	worker.setCordoned(cordoned)
+	if cordoned {
+		logger.GetLogger().Infof("Cordoned worker %s", workerID)
+	} else {
+		logger.GetLogger().Infof("Uncordoned worker %s", workerID)
+	}
+	return nil
+}
+
+// Workers returns the workers currently in the pool.
+func (p *WorkerPool) Workers() []*WorkerClient {
+	return p.snapshot()
+}
+
+func (p *WorkerPool) worker(workerID string) (*WorkerClient, error) {
+	for _, worker := range p.snapshot() {
+		if worker.id == workerID {
+			return worker, nil
 		}
 	}
+	return nil, fmt.Errorf("%w: %s", ErrWorkerNotFound, workerID)
+}
+
+// save persists the worker list when persistence is enabled. The caller
+// must hold p.reconfigure.
+func (p *WorkerPool) save(workers []config.WorkerConfig) error {
+	if p.persist == nil {
+		return nil
+	}
+	if err := p.persist(workers); err != nil {
+		return fmt.Errorf("failed to persist workers: %w", err)
+	}
+	return nil
+}
 
-	return nil, fmt.Errorf("worker %s not found", workerID)
+func withWorkers(cfg *config.Config, workers []config.WorkerConfig) *config.Config {
+	updated := *cfg
+	updated.Workers = workers
+	return &updated
 }
 
-func (p *WorkerPool) GetAllWorkerStatuses() (map[string]*pb.StatusResponse, error) {
-	statuses := make(map[string]*pb.StatusResponse)
+// CanPlace reports why a task could not be dispatched right now: no worker
+// accepts its type, or none of those matches its selector.
+func (p *WorkerPool) CanPlace(taskType string, placement Placement) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
+	_, err := p.eligibleWorkers(taskType, placement)
+	return err
+}
+
+// eligibleWorkers returns the workers that accept the task type and satisfy
+// the placement selector, narrowed to those with the best preference score.
+// The caller must hold p.mu.
+func (p *WorkerPool) eligibleWorkers(taskType string, placement Placement) ([]*WorkerClient, error) {
+	if len(p.workers) == 0 {
+		return nil, ErrNoWorkers
+	}
+
+	capable := make([]*WorkerClient, 0, len(p.workers))
+	cordoned := 0
 	for _, worker := range p.workers {
-		ctx, cancel := context.WithTimeout(context.Background(), worker.timeout)
-		defer cancel()
+		if !worker.Supports(taskType) {
+			continue
+		}
+		if worker.Cordoned() {
+			cordoned++
+			continue
+		}
+		capable = append(capable, worker)
+	}
+	if len(capable) == 0 && cordoned > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrWorkersCordoned, taskType)
+	}
+	if len(capable) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoCapableWorker, taskType)
+	}
+
+	matching := make([]*WorkerClient, 0, len(capable))
+	for _, worker := range capable {
+		if placement.Selector.Matches(worker.Labels()) {
+			matching = append(matching, worker)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoMatchingWorker, placement.Selector)
+	}
+
+	if len(placement.Preferences) == 0 {
+		return matching, nil
+	}
+
+	// Preferences are soft: keep the highest scoring workers, whatever the
+	// score, so a task still runs when no worker matches any preference
+	var preferred []*WorkerClient
+	best := 0
+	for _, worker := range matching {
+		score := placement.score(worker.Labels())
+		switch {
+		case len(preferred) == 0 || score > best:
+			preferred = []*WorkerClient{worker}
+			best = score
+		case score == best:
+			preferred = append(preferred, worker)
+		}
+	}
+	return preferred, nil
+}
+
+func (p *WorkerPool) ProcessTask(ctx context.Context, task *Task) (*pb.TaskResponse, error) {
+	worker, err := p.pick(task.TaskType, task.Placement)
+	if err != nil {
+		return nil, err
+	}
+	defer worker.inflight.Done()
+
+	// The deadline reaches the worker, which abandons the task with it
+	timeout := worker.Timeout()
+	if task.Timeout > 0 {
+		timeout = task.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req := &pb.TaskRequest{
+		TaskId:      task.ID,
+		TaskType:    task.TaskType,
+		Payload:     task.Payload,
+		PayloadBlob: task.PayloadBlob,
+		ContentType: task.ContentType,
+		Metadata:    task.Metadata,
+	}
+
+	// Payloads and results too large for one message are streamed in chunks
+	chunkSize := p.Config().GetChunkSize()
+	if len(task.Payload) > chunkSize {
+		if err := worker.uploadPayload(ctx, task.ID, task.Payload, chunkSize); err != nil {
+			return nil, err
+		}
+		req.Payload = nil
+		req.PayloadUploaded = true
+	}
+
+	resp, err := worker.client.ProcessTask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ResultStreamed {
+		if resp.Result, err = worker.downloadResult(ctx, task.ID, resp.ResultSize, resp.ResultSha256); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// pick selects the worker for a task and registers the task as in flight on
+// it; the caller must call inflight.Done when the task has finished.
+func (p *WorkerPool) pick(taskType string, placement Placement) (*WorkerClient, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	eligible, err := p.eligibleWorkers(taskType, placement)
+	if err != nil {
+		return nil, err
+	}
+
+	var worker *WorkerClient
+	if placement.RoutingKey != "" {
+		// Consistent hashing keeps tasks with the same key on one worker
+		worker = rendezvousPick(placement.RoutingKey, eligible)
+	} else {
+		// Simple round-robin selection among the eligible workers
+		workerIdx := p.counter.Add(1) % int64(len(eligible))
 
-		req := &pb.StatusRequest{
-			WorkerId: worker.id,
+		// Optional: reset the counter when it wraps around
+		if workerIdx == 0 {
+			p.counter.Store(0)
 		}
 
-		status, err := worker.client.GetStatus(ctx, req)
+		worker = eligible[workerIdx]
+	}
+
+	worker.inflight.Add(1)
+	return worker, nil
+}
+
+// snapshot returns the current workers for iteration without holding the lock.
+func (p *WorkerPool) snapshot() []*WorkerClient {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*WorkerClient(nil), p.workers...)
+}
+
+func (p *WorkerPool) GetWorkerStatus(workerID string) (*pb.StatusResponse, error) {
+	worker, err := p.worker(workerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return worker.getStatus()
+}
+
+func (p *WorkerPool) GetAllWorkerStatuses() (map[string]*pb.StatusResponse, error) {
+	statuses := make(map[string]*pb.StatusResponse)
+
+	for _, worker := range p.snapshot() {
+		status, err := worker.getStatus()
 		if err != nil {
 			logger.GetLogger().Warnf("Failed to get status for worker %s: %v", worker.id, err)
 			continue
@@ -134,6 +680,9 @@ func (p *WorkerPool) GetAllWorkerStatuses() (map[string]*pb.StatusResponse, erro
 }
 
 func (p *WorkerPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	for _, worker := range p.workers {
 		if worker.conn != nil {
 			worker.conn.Close()
@@ -0,0 +1,73 @@
+package master
+
+import (
+	"testing"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+)
+
+func workerAddrs(p *WorkerPool) map[string]string {
+	addrs := make(map[string]string)
+	for _, worker := range p.Workers() {
+		addrs[worker.id] = worker.addr
+	}
+	return addrs
+}
+
+func TestReconcileKeepsWorkersAddedAtRuntime(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Workers = []config.WorkerConfig{{ID: "worker-1", URL: "localhost:1"}}
+	pool, err := NewWorkerPool(cfg)
+	if err != nil {
+		t.Fatalf("NewWorkerPool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if _, err := pool.AddWorker(config.WorkerConfig{ID: "worker-2", URL: "localhost:2"}); err != nil {
+		t.Fatalf("AddWorker: %v", err)
+	}
+
+	// A reload of the same file keeps the added worker
+	reloaded := config.Defaults()
+	reloaded.Workers = []config.WorkerConfig{{ID: "worker-1", URL: "localhost:1"}}
+	pool.Reconcile(reloaded)
+	if got := workerAddrs(pool); got["worker-2"] != "localhost:2" || len(got) != 2 {
+		t.Fatalf("workers after reload = %v, want worker-2 kept", got)
+	}
+
+	// A file that lists the ID itself takes its place
+	reloaded = config.Defaults()
+	reloaded.Workers = []config.WorkerConfig{{ID: "worker-2", URL: "localhost:3"}}
+	pool.Reconcile(reloaded)
+	if got := workerAddrs(pool); got["worker-2"] != "localhost:3" || len(got) != 1 {
+		t.Fatalf("workers after reload = %v, want only worker-2 at localhost:3", got)
+	}
+	reloaded = config.Defaults()
+	reloaded.Workers = []config.WorkerConfig{{ID: "worker-1", URL: "localhost:1"}}
+	pool.Reconcile(reloaded)
+	if got := workerAddrs(pool); len(got) != 1 || got["worker-1"] == "" {
+		t.Fatalf("workers after reload = %v, want only worker-1", got)
+	}
+}
+
+func TestReconcileDropsRemovedRuntimeWorkers(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Workers = []config.WorkerConfig{{ID: "worker-1", URL: "localhost:1"}}
+	pool, err := NewWorkerPool(cfg)
+	if err != nil {
+		t.Fatalf("NewWorkerPool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if _, err := pool.AddWorker(config.WorkerConfig{ID: "worker-2", URL: "localhost:2"}); err != nil {
+		t.Fatalf("AddWorker: %v", err)
+	}
+	if err := pool.RemoveWorker("worker-2"); err != nil {
+		t.Fatalf("RemoveWorker: %v", err)
+	}
+
+	pool.Reconcile(cfg)
+	if got := workerAddrs(pool); len(got) != 1 {
+		t.Fatalf("workers after reload = %v, want only worker-1", got)
+	}
+}
@@ -0,0 +1,230 @@
+package master
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MasterServer exposes the master over gRPC. It submits tasks through the
+// same TaskManager path as the REST API.
+type MasterServer struct {
+	pb.UnimplementedMasterServiceServer
+	workerPool *WorkerPool
+	tasks      *TaskManager
+}
+
+// ServerOptions returns the gRPC server options for the configured message
+// size limit.
+func ServerOptions(cfg *config.Config) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.GetMaxMessageSize()),
+		grpc.MaxSendMsgSize(cfg.GetMaxMessageSize()),
+	}
+}
+
+func NewMasterServer(workerPool *WorkerPool, tasks *TaskManager) *MasterServer {
+	return &MasterServer{
+		workerPool: workerPool,
+		tasks:      tasks,
+	}
+}
+
+func (s *MasterServer) SubmitTask(ctx context.Context, req *pb.SubmitTaskRequest) (*pb.Task, error) {
+	taskReq, err := taskRequestFromProto(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Once the task exists, failures to run it are reported on the task
+	task, err := s.tasks.SubmitRequest(ctx, taskReq, req.Wait)
+	if task == nil {
+		return nil, taskStatusError(err)
+	}
+
+	return taskToProto(task), nil
+}
+
+func (s *MasterServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Task, error) {
+	task, err := s.tasks.Get(req.TaskId)
+	if err != nil {
+		return nil, taskStatusError(err)
+	}
+
+	return taskToProto(task), nil
+}
+
+func (s *MasterServer) CancelTask(ctx context.Context, req *pb.CancelTaskRequest) (*pb.Task, error) {
+	task, err := s.tasks.Cancel(req.TaskId)
+	if err != nil {
+		return nil, taskStatusError(err)
+	}
+
+	return taskToProto(task), nil
+}
+
+func (s *MasterServer) ListWorkers(ctx context.Context, req *pb.ListWorkersRequest) (*pb.ListWorkersResponse, error) {
+	workers := s.workerPool.Workers()
+	resp := &pb.ListWorkersResponse{
+		Workers: make([]*pb.Worker, len(workers)),
+	}
+	for i, worker := range workers {
+		resp.Workers[i] = &pb.Worker{
+			Id:        worker.id,
+			Url:       worker.addr,
+			Cordoned:  worker.Cordoned(),
+			TaskTypes: worker.TaskTypes(),
+			Labels:    worker.Labels(),
+		}
+	}
+
+	return resp, nil
+}
+
+// WatchTask streams the task's current state and every later change until
+// it finishes or the client goes away.
+func (s *MasterServer) WatchTask(req *pb.WatchTaskRequest, stream pb.MasterService_WatchTaskServer) error {
+	updates, stop, err := s.tasks.Watch(req.TaskId)
+	if err != nil {
+		return taskStatusError(err)
+	}
+	defer stop()
+
+	for {
+		select {
+		case task, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(taskToProto(task)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// taskStatusError maps task errors to gRPC status codes through the same
+// classification as REST error responses. Errors from a worker keep the
+// status code the worker gave.
+func taskStatusError(err error) error {
+	code := errorCode(err)
+	switch code {
+	case CodeInternal:
+		logger.GetLogger().Errorf("gRPC request failed: %v", err)
+	case CodeWorkerError:
+		if s, ok := status.FromError(err); ok {
+			return status.Error(s.Code(), err.Error())
+		}
+	}
+	return status.Error(code.GRPCCode(), err.Error())
+}
+
+func taskRequestFromProto(req *pb.SubmitTaskRequest) (TaskRequest, error) {
+	taskReq := TaskRequest{
+		TaskType:       req.TaskType,
+		ContentType:    req.ContentType,
+		Metadata:       req.Metadata,
+		Delay:          req.Delay,
+		Timeout:        req.Timeout,
+		RoutingKey:     req.RoutingKey,
+		IdempotencyKey: req.IdempotencyKey,
+		data:           req.Payload,
+	}
+
+	if req.RunAt != "" {
+		runAt, err := time.Parse(time.RFC3339, req.RunAt)
+		if err != nil {
+			return TaskRequest{}, fmt.Errorf("invalid run_at %q: %w", req.RunAt, err)
+		}
+		taskReq.RunAt = &runAt
+	}
+
+	selector, err := ParseSelector(req.Selector)
+	if err != nil {
+		return TaskRequest{}, err
+	}
+	taskReq.Selector = selector
+
+	for _, pref := range req.Preferences {
+		selector, err := ParseSelector(pref.Selector)
+		if err != nil {
+			return TaskRequest{}, err
+		}
+		taskReq.Preferences = append(taskReq.Preferences, Preference{
+			Selector: selector,
+			Weight:   int(pref.Weight),
+		})
+	}
+
+	return taskReq, nil
+}
+
+var taskStates = map[TaskState]pb.TaskState{
+	TaskStateScheduled: pb.TaskState_TASK_STATE_SCHEDULED,
+	TaskStateRunning:   pb.TaskState_TASK_STATE_RUNNING,
+	TaskStateCompleted: pb.TaskState_TASK_STATE_COMPLETED,
+	TaskStateFailed:    pb.TaskState_TASK_STATE_FAILED,
+	TaskStateCancelled: pb.TaskState_TASK_STATE_CANCELLED,
+	TaskStateTimedOut:  pb.TaskState_TASK_STATE_TIMED_OUT,
+}
+
+var taskErrorCodes = map[ErrorCode]pb.TaskErrorCode{
+	CodeTaskFailed:       pb.TaskErrorCode_TASK_ERROR_CODE_TASK_FAILED,
+	CodeInvalidPayload:   pb.TaskErrorCode_TASK_ERROR_CODE_INVALID_PAYLOAD,
+	CodeInvalidResult:    pb.TaskErrorCode_TASK_ERROR_CODE_INVALID_RESULT,
+	CodeCancelled:        pb.TaskErrorCode_TASK_ERROR_CODE_CANCELLED,
+	CodeDeadlineExceeded: pb.TaskErrorCode_TASK_ERROR_CODE_DEADLINE_EXCEEDED,
+	CodeUnavailable:      pb.TaskErrorCode_TASK_ERROR_CODE_UNAVAILABLE,
+	CodeWorkerError:      pb.TaskErrorCode_TASK_ERROR_CODE_WORKER_ERROR,
+	CodeInternal:         pb.TaskErrorCode_TASK_ERROR_CODE_INTERNAL,
+	CodeUnknownTaskType:  pb.TaskErrorCode_TASK_ERROR_CODE_UNKNOWN_TASK_TYPE,
+	CodeNoMatchingWorker: pb.TaskErrorCode_TASK_ERROR_CODE_NO_MATCHING_WORKER,
+}
+
+func taskToProto(task *Task) *pb.Task {
+	return &pb.Task{
+		TaskId:            task.ID,
+		TaskType:          task.TaskType,
+		Payload:           task.Payload,
+		PayloadBlob:       task.PayloadBlob,
+		ContentType:       task.ContentType,
+		Metadata:          task.Metadata,
+		State:             taskStates[task.State],
+		Success:           task.Success,
+		Result:            task.Result,
+		ResultContentType: task.ResultContentType,
+		ResultMetadata:    task.ResultMetadata,
+		Cached:            task.Cached,
+		Timeout:           formatDuration(task.Timeout),
+		Error:             task.Error,
+		ErrorCode:         taskErrorCodes[task.ErrorCode],
+		CreatedAt:         task.CreatedAt.Format(time.RFC3339Nano),
+		RunAt:             formatTime(task.RunAt),
+		StartedAt:         formatTime(task.StartedAt),
+		FinishedAt:        formatTime(task.FinishedAt),
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,78 @@
+package master_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/master"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/testcluster"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSubmitTaskCancelledByCaller(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+	c.Worker("worker-1").SetLatency(time.Minute)
+
+	events, stop := c.Tasks.Subscribe()
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := c.MasterClient().SubmitTask(ctx, &pb.SubmitTaskRequest{
+		TaskType: "compute",
+		Payload:  []byte("2+2"),
+		Wait:     true,
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("SubmitTask error = %v, want DeadlineExceeded", err)
+	}
+
+	// The dispatch stops with the call rather than after the worker's minute
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case task := <-events:
+			if !task.State.Finished() {
+				continue
+			}
+			if task.State != master.TaskStateCancelled || task.ErrorCode != master.CodeCancelled {
+				t.Fatalf("task finished as %s (%s), want cancelled", task.State, task.ErrorCode)
+			}
+			return
+		case <-timeout:
+			t.Fatal("task still running after its submission was cancelled")
+		}
+	}
+}
+
+func TestSubmitTaskReturnsFailedTask(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+	c.Worker("worker-1").Kill()
+
+	req := &pb.SubmitTaskRequest{
+		TaskType:       "compute",
+		Payload:        []byte("2+2"),
+		Wait:           true,
+		IdempotencyKey: "order-1",
+	}
+	task, err := c.MasterClient().SubmitTask(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SubmitTask: %v", err)
+	}
+	if task.TaskId == "" || task.State != pb.TaskState_TASK_STATE_FAILED || task.ErrorCode != pb.TaskErrorCode_TASK_ERROR_CODE_UNAVAILABLE {
+		t.Fatalf("got task %q in state %s with error code %s, want a failed task with error code unavailable", task.TaskId, task.State, task.ErrorCode)
+	}
+
+	// Retrying returns the task stored under the idempotency key
+	again, err := c.MasterClient().SubmitTask(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SubmitTask retry: %v", err)
+	}
+	if again.TaskId != task.TaskId {
+		t.Errorf("retry returned task %s, want %s", again.TaskId, task.TaskId)
+	}
+}
@@ -1,33 +1,170 @@
 package master
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
 	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 type TaskRequest struct {
 	TaskType string `json:"task_type" binding:"required"`
-	Payload  string `json:"payload" binding:"required"`
+	// Payload is text when it is a JSON string, and JSON otherwise; binary
+	// payloads go in PayloadBase64 or are uploaded raw
+	Payload       json.RawMessage   `json:"payload,omitempty"`
+	PayloadBase64 string            `json:"payload_base64,omitempty"`
+	ContentType   string            `json:"content_type,omitempty"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	// RunAt (RFC3339) or Delay (Go duration) hold the task back until later
+	RunAt *time.Time `json:"run_at,omitempty"`
+	Delay string     `json:"delay,omitempty"`
+	// Timeout (Go duration) bounds how long the task may run once
+	// dispatched; it defaults to the task type's timeout, then grpc.timeout
+	Timeout string `json:"timeout,omitempty"`
+	// Selector restricts the task to workers whose labels match it, while
+	// Preferences only rank the workers that do
+	Selector    Selector     `json:"selector"`
+	Preferences []Preference `json:"preferences,omitempty"`
+	// RoutingKey sends tasks sharing the key to the same worker
+	RoutingKey string `json:"routing_key,omitempty"`
+	// IdempotencyKey makes retried submissions return the original task; over
+	// REST it is taken from the Idempotency-Key header
+	IdempotencyKey string `json:"-"`
+
+	// data is a payload received as raw bytes, over gRPC or a raw upload
+	data []byte
 }
 
 type TaskResponse struct {
-	TaskID  string `json:"task_id"`
-	Success bool   `json:"success"`
-	Result  string `json:"result,omitempty"`
-	Error   string `json:"error,omitempty"`
+	TaskID            string            `json:"task_id"`
+	State             TaskState         `json:"state"`
+	Success           bool              `json:"success"`
+	Result            json.RawMessage   `json:"result,omitempty"`
+	ResultBase64      string            `json:"result_base64,omitempty"`
+	ResultContentType string            `json:"result_content_type,omitempty"`
+	ResultMetadata    map[string]string `json:"result_metadata,omitempty"`
+	Cached            bool              `json:"cached,omitempty"`
+	Error             string            `json:"error,omitempty"`
+	ErrorCode         ErrorCode         `json:"error_code,omitempty"`
+	RunAt             *time.Time        `json:"run_at,omitempty"`
+}
+
+func taskResponse(task *Task) TaskResponse {
+	result, resultBase64 := encodeData(task.Result, task.ResultContentType)
+	return TaskResponse{
+		TaskID:            task.ID,
+		State:             task.State,
+		Success:           task.Success,
+		Result:            result,
+		ResultBase64:      resultBase64,
+		ResultContentType: task.ResultContentType,
+		ResultMetadata:    task.ResultMetadata,
+		Cached:            task.Cached,
+		Error:             task.Error,
+		ErrorCode:         task.ErrorCode,
+		RunAt:             task.RunAt,
+	}
+}
+
+// rawTaskRequest reads a task uploaded as the raw request body. The body's
+// Content-Type becomes the payload's, and the remaining fields come from the
+// query string and X-Task-Metadata-* headers.
+func rawTaskRequest(c *gin.Context) (TaskRequest, error) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return TaskRequest{}, fmt.Errorf("failed to read payload: %w", err)
+	}
+
+	req := TaskRequest{
+		TaskType:    c.Query("task_type"),
+		ContentType: c.GetHeader("Content-Type"),
+		Metadata:    metadataFromHeader(c.Request.Header),
+		Delay:       c.Query("delay"),
+		Timeout:     c.Query("timeout"),
+		RoutingKey:  c.Query("routing_key"),
+		data:        data,
+	}
+	if req.TaskType == "" {
+		return TaskRequest{}, fmt.Errorf("the task_type query parameter is required")
+	}
+	if len(data) == 0 {
+		return TaskRequest{}, fmt.Errorf("payload is required")
+	}
+
+	if runAt := c.Query("run_at"); runAt != "" {
+		t, err := time.Parse(time.RFC3339, runAt)
+		if err != nil {
+			return TaskRequest{}, fmt.Errorf("invalid run_at %q: %w", runAt, err)
+		}
+		req.RunAt = &t
+	}
+
+	if req.Selector, err = ParseSelector(c.Query("selector")); err != nil {
+		return TaskRequest{}, err
+	}
+
+	return req, nil
+}
+
+func (r *TaskRequest) placement() Placement {
+	return Placement{
+		Selector:    r.Selector,
+		Preferences: r.Preferences,
+		RoutingKey:  r.RoutingKey,
+	}
+}
+
+// runTime resolves the run_at and delay fields into the time the task should
+// be dispatched at. The zero time means immediately.
+func (r *TaskRequest) runTime() (time.Time, error) {
+	if r.RunAt != nil && r.Delay != "" {
+		return time.Time{}, fmt.Errorf("only one of run_at or delay may be set")
+	}
+	if r.RunAt != nil {
+		return *r.RunAt, nil
+	}
+	if r.Delay != "" {
+		delay, err := time.ParseDuration(r.Delay)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid delay %q: %w", r.Delay, err)
+		}
+		if delay < 0 {
+			return time.Time{}, fmt.Errorf("delay must not be negative")
+		}
+		return time.Now().Add(delay), nil
+	}
+	return time.Time{}, nil
+}
+
+// requestedTimeout parses the timeout field, returning 0 when it is unset.
+func (r *TaskRequest) requestedTimeout() (time.Duration, error) {
+	if r.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(r.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", r.Timeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("timeout must be positive")
+	}
+	return timeout, nil
 }
 
 type StatusResponse struct {
-	WorkerID    string `json:"worker_id"`
-	Status      string `json:"status"`
-	ActiveTasks int32  `json:"active_tasks"`
+	WorkerID    string            `json:"worker_id"`
+	Status      string            `json:"status"`
+	ActiveTasks int32             `json:"active_tasks"`
+	TaskTypes   []string          `json:"task_types,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
 }
 
 type AllStatusResponse struct {
@@ -35,11 +172,27 @@ type AllStatusResponse struct {
 	Total   int                       `json:"total_workers"`
 }
 
-func SetupRoutes(workerPool *WorkerPool, config *config.Config) *gin.Engine {
+// taskStatus is the status of a submission that created the task. A task
+// that ran and failed is still a successful request, while one that never
+// got an answer from its worker takes the status of the failure.
+func taskStatus(task *Task) int {
+	switch {
+	case task.State == TaskStateScheduled, task.State == TaskStateRunning:
+		return http.StatusAccepted
+	case task.Success:
+		return http.StatusOK
+	}
+	return task.ErrorCode.HTTPStatus()
+}
+
+func SetupRoutes(workerPool *WorkerPool, tasks *TaskManager, scheduler *Scheduler, config *config.Config) *gin.Engine {
 	r := gin.Default()
 
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
+		// Reflect reloaded configuration rather than the one at startup
+		config := workerPool.Config()
+
 		c.JSON(http.StatusOK, gin.H{
 			"status": "healthy",
 			"time":   time.Now(),
@@ -51,40 +204,176 @@ func SetupRoutes(workerPool *WorkerPool, config *config.Config) *gin.Engine {
 		})
 	})
 
+	// /openapi.json documents the API, and request bodies are validated
+	// against it
+	api := newOpenAPI()
+	r.GET("/openapi.json", api.serve)
+
+	// Every other endpoint is served under /v1, and at its unversioned path
+	// as a deprecated alias
+	v1 := r.Group("/v1", api.validate)
+	legacy := r.Group("/", deprecatedAlias, api.validate)
+	for _, group := range []*gin.RouterGroup{v1, legacy} {
+		setupTaskRoutes(group, workerPool, tasks)
+		setupScheduleRoutes(group, scheduler)
+		setupWorkerRoutes(group, workerPool)
+		setupBlobRoutes(group, tasks.Blobs())
+	}
+
+	// Web dashboard, built on the endpoints above
+	setupDashboard(r)
+
+	api.mustMatch(r.Routes())
+
+	return r
+}
+
+// deprecatedAlias marks responses from the unversioned paths as deprecated,
+// pointing to their /v1 successors.
+func deprecatedAlias(c *gin.Context) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", apiPrefix+c.Request.URL.Path))
+	c.Next()
+}
+
+const (
+	defaultTaskListLimit = 50
+	maxTaskListLimit     = 1000
+)
+
+// taskListLimit parses the limit of GET /tasks, capping it at
+// maxTaskListLimit.
+func taskListLimit(value string) (int, error) {
+	if value == "" {
+		return defaultTaskListLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("limit must be a positive integer, got %q", value)
+	}
+	return min(limit, maxTaskListLimit), nil
+}
+
+func setupTaskRoutes(r *gin.RouterGroup, workerPool *WorkerPool, tasks *TaskManager) {
 	// Submit task endpoint
 	r.POST("/tasks", func(c *gin.Context) {
+		// JSON describes the task; any other content type is the raw payload
 		var req TaskRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		var err error
+		switch c.ContentType() {
+		case "", "application/json":
+			err = c.ShouldBindJSON(&req)
+		default:
+			req, err = rawTaskRequest(c)
+		}
+		if err != nil {
+			respondError(c, CodeInvalidArgument, err)
+			return
+		}
+
+		req.IdempotencyKey = c.GetHeader("Idempotency-Key")
+
+		// ?wait=false returns as soon as the task is accepted
+		wait := c.DefaultQuery("wait", "true") != "false"
+
+		// Once the task exists, failures to run it are reported on the task
+		task, err := tasks.SubmitRequest(c.Request.Context(), req, wait)
+		if task == nil {
+			respondError(c, errorCode(err), err)
 			return
 		}
 
-		// Generate task ID
-		taskID := uuid.New().String()
-		logger.GetLogger().Infof("Received task: %s, Type: %s, Payload: %s", taskID, req.TaskType, req.Payload)
+		c.JSON(taskStatus(task), taskResponse(task))
+	})
 
-		// Process task via worker
-		resp, err := workerPool.ProcessTask(taskID, req.TaskType, req.Payload)
+	// List tasks endpoint; the most recent ones first
+	r.GET("/tasks", func(c *gin.Context) {
+		limit, err := taskListLimit(c.Query("limit"))
 		if err != nil {
-			logger.GetLogger().Errorf("Failed to process task %s: %v", taskID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": fmt.Sprintf("Failed to process task: %v", err),
-			})
+			respondError(c, CodeInvalidArgument, err)
 			return
 		}
 
-		taskResp := TaskResponse{
-			TaskID:  resp.TaskId,
-			Success: resp.Success,
-			Result:  resp.Result,
-			Error:   resp.Error,
+		c.JSON(http.StatusOK, gin.H{"tasks": tasks.List(limit)})
+	})
+
+	// Get specific task endpoint
+	r.GET("/tasks/:task_id", func(c *gin.Context) {
+		task, err := tasks.Get(c.Param("task_id"))
+		if err != nil {
+			respondError(c, errorCode(err), err)
+			return
 		}
 
-		if resp.Success {
-			c.JSON(http.StatusOK, taskResp)
-		} else {
-			c.JSON(http.StatusBadRequest, taskResp)
+		c.JSON(http.StatusOK, task)
+	})
+
+	// Task result endpoint; returns the result as raw bytes with its content
+	// type, once the task has completed
+	r.GET("/tasks/:task_id/result", func(c *gin.Context) {
+		task, err := tasks.Get(c.Param("task_id"))
+		if err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+		if task.State != TaskStateCompleted {
+			respondError(c, CodeConflict, fmt.Errorf("task is %s and has no result", task.State))
+			return
 		}
+
+		setMetadataHeader(c.Writer.Header(), task.ResultMetadata)
+		c.Data(http.StatusOK, contentTypeOr(task.ResultContentType, ContentTypeBinary), task.Result)
+	})
+
+	// Cancel task endpoint
+	r.POST("/tasks/:task_id/cancel", func(c *gin.Context) {
+		task, err := tasks.Cancel(c.Param("task_id"))
+		if err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, task)
+	})
+
+	// Task events endpoint; streams every task state change as server-sent
+	// events until the client disconnects
+	r.GET("/events", func(c *gin.Context) {
+		events, stop := tasks.Subscribe()
+		defer stop()
+
+		// Send the headers right away: once they arrive the client knows it
+		// is subscribed
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case task := <-events:
+				c.SSEvent("task", task)
+				return true
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	})
+
+	// List task types endpoint
+	r.GET("/task-types", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"task_types": tasks.TaskTypes().List()})
+	})
+
+	// Get specific task type endpoint
+	r.GET("/task-types/:name", func(c *gin.Context) {
+		taskType, ok := tasks.TaskTypes().Get(c.Param("name"))
+		if !ok {
+			respondError(c, CodeNotFound, fmt.Errorf("task type %s is not declared", c.Param("name")))
+			return
+		}
+
+		c.JSON(http.StatusOK, taskType)
 	})
 
 	// Get specific worker status endpoint
@@ -92,11 +381,13 @@ func SetupRoutes(workerPool *WorkerPool, config *config.Config) *gin.Engine {
 		workerID := c.Param("worker_id")
 
 		resp, err := workerPool.GetWorkerStatus(workerID)
+		if errors.Is(err, ErrWorkerNotFound) {
+			respondError(c, CodeNotFound, err)
+			return
+		}
 		if err != nil {
 			logger.GetLogger().Errorf("Failed to get status for worker %s: %v", workerID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": fmt.Sprintf("Failed to get worker status: %v", err),
-			})
+			respondError(c, errorCode(err), fmt.Errorf("failed to get worker status: %w", err))
 			return
 		}
 
@@ -104,6 +395,8 @@ func SetupRoutes(workerPool *WorkerPool, config *config.Config) *gin.Engine {
 			WorkerID:    resp.WorkerId,
 			Status:      resp.Status,
 			ActiveTasks: resp.ActiveTasks,
+			TaskTypes:   resp.TaskTypes,
+			Labels:      resp.Labels,
 		}
 
 		c.JSON(http.StatusOK, statusResp)
@@ -114,9 +407,7 @@ func SetupRoutes(workerPool *WorkerPool, config *config.Config) *gin.Engine {
 		statuses, err := workerPool.GetAllWorkerStatuses()
 		if err != nil {
 			logger.GetLogger().Errorf("Failed to get all workers status: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": fmt.Sprintf("Failed to get workers status: %v", err),
-			})
+			respondError(c, errorCode(err), fmt.Errorf("failed to get workers status: %w", err))
 			return
 		}
 
@@ -126,6 +417,8 @@ func SetupRoutes(workerPool *WorkerPool, config *config.Config) *gin.Engine {
 				WorkerID:    status.WorkerId,
 				Status:      status.Status,
 				ActiveTasks: status.ActiveTasks,
+				TaskTypes:   status.TaskTypes,
+				Labels:      status.Labels,
 			}
 		}
 
@@ -136,6 +429,4 @@ func SetupRoutes(workerPool *WorkerPool, config *config.Config) *gin.Engine {
 
 		c.JSON(http.StatusOK, response)
 	})
-
-	return r
 }
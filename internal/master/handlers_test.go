@@ -0,0 +1,85 @@
+package master_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/master"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/testcluster"
+)
+
+func TestListTasks(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+
+	var submitted []string
+	for i := 0; i < 3; i++ {
+		rec := c.Do(http.MethodPost, "/v1/tasks?wait=false", master.TaskRequest{
+			TaskType: "compute",
+			Payload:  json.RawMessage(fmt.Sprintf(`"%d+%d"`, i, i)),
+			Delay:    "1h",
+		})
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("POST /v1/tasks = %d %s", rec.Code, rec.Body)
+		}
+		var task master.TaskResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &task); err != nil {
+			t.Fatalf("decoding task: %v", err)
+		}
+		submitted = append(submitted, task.TaskID)
+	}
+
+	rec := c.Do(http.MethodGet, "/v1/tasks?limit=2", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /v1/tasks = %d %s", rec.Code, rec.Body)
+	}
+	var list struct {
+		Tasks []master.Task `json:"tasks"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decoding tasks: %v", err)
+	}
+	want := []string{submitted[2], submitted[1]}
+	if len(list.Tasks) != len(want) {
+		t.Fatalf("got %d tasks, want %d", len(list.Tasks), len(want))
+	}
+	for i, task := range list.Tasks {
+		if task.ID != want[i] {
+			t.Errorf("tasks[%d] = %s, want %s", i, task.ID, want[i])
+		}
+	}
+
+	for _, limit := range []string{"0", "-1", "ten"} {
+		if rec := c.Do(http.MethodGet, "/v1/tasks?limit="+limit, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /v1/tasks?limit=%s = %d, want %d", limit, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestCloseWaitsForBackgroundTasks(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+	c.Worker("worker-1").SetLatency(200 * time.Millisecond)
+
+	rec := c.Do(http.MethodPost, "/v1/tasks?wait=false", master.TaskRequest{
+		TaskType: "compute",
+		Payload:  json.RawMessage(`"2+2"`),
+	})
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /v1/tasks = %d %s", rec.Code, rec.Body)
+	}
+	var resp master.TaskResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding task: %v", err)
+	}
+
+	c.Close()
+	task, err := c.Tasks.Get(resp.TaskID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if task.State != master.TaskStateCompleted {
+		t.Errorf("task %s after Close, want completed", task.State)
+	}
+}
@@ -0,0 +1,732 @@
+package master
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/jsonschema"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiPrefix is the path the current version of the API is served under.
+const apiPrefix = "/v1"
+
+// schema is a JSON Schema, or an OpenAPI schema object.
+type schema map[string]interface{}
+
+// operation documents an endpoint. Unless it is unversioned, it is served
+// under apiPrefix, and at its plain path as a deprecated alias.
+type operation struct {
+	method      string
+	path        string
+	id          string
+	summary     string
+	unversioned bool
+	params      []parameter
+	request     []body
+	responses   []response
+}
+
+type parameter struct {
+	name        string
+	in          string
+	description string
+	schema      schema
+}
+
+// body is a request or response body. Its value is a Go value whose type
+// gives the schema, a schema, a listOf, a slice of alternatives, or nil for
+// an empty body.
+type body struct {
+	contentType string
+	value       interface{}
+}
+
+type response struct {
+	status      int
+	description string
+	body
+}
+
+// listOf is an object holding a list of values under key, e.g. {"workers": []}.
+type listOf struct {
+	key  string
+	item interface{}
+}
+
+func jsonBody(value interface{}) body {
+	return body{contentType: "application/json", value: value}
+}
+
+func binaryBody(contentType string) body {
+	return body{contentType: contentType, value: schema{"type": "string", "format": "binary"}}
+}
+
+func errorResponse(status int, description string) response {
+	return response{status: status, description: description, body: jsonBody(ErrorResponse{})}
+}
+
+var (
+	stringSchema  = schema{"type": "string"}
+	booleanSchema = schema{"type": "boolean"}
+)
+
+var apiOperations = []operation{
+	{
+		method: http.MethodGet, path: "/health", id: "health", unversioned: true,
+		summary: "Check the master's health",
+		responses: []response{
+			{http.StatusOK, "The master is healthy", jsonBody(schema{
+				"type": "object",
+				"properties": schema{
+					"status": stringSchema,
+					"time":   schema{"type": "string", "format": "date-time"},
+					"config": schema{
+						"type": "object",
+						"properties": schema{
+							"server_port":   stringSchema,
+							"workers_count": schema{"type": "integer"},
+							"grpc_timeout":  stringSchema,
+						},
+					},
+				},
+			})},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/openapi.json", id: "getOpenAPI", unversioned: true,
+		summary: "Get this OpenAPI document",
+		responses: []response{
+			{http.StatusOK, "The OpenAPI document", jsonBody(schema{"type": "object"})},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/dashboard/*filepath", id: "getDashboard", unversioned: true,
+		summary: "Get a file of the web dashboard; /dashboard/ is its page",
+		responses: []response{
+			{http.StatusOK, "The file", binaryBody("text/html")},
+			{status: http.StatusNotFound, description: "The file does not exist"},
+		},
+	},
+	{
+		method: http.MethodHead, path: "/dashboard/*filepath", id: "headDashboard", unversioned: true,
+		summary: "Check that a file of the web dashboard exists",
+		responses: []response{
+			{status: http.StatusOK, description: "The file exists"},
+			{status: http.StatusNotFound, description: "The file does not exist"},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/tasks", id: "submitTask",
+		summary: "Submit a task, described as JSON or uploaded as the raw payload",
+		params: []parameter{
+			{"wait", "query", "Wait until a task that is due now has finished; defaults to true", booleanSchema},
+			{"Idempotency-Key", "header", "Makes retries of the submission return the task created first", stringSchema},
+			{"task_type", "query", "The task type of a raw upload", stringSchema},
+			{"run_at", "query", "When to run a raw upload, as RFC 3339", stringSchema},
+			{"delay", "query", "How long to hold a raw upload back, as a Go duration", stringSchema},
+			{"timeout", "query", "The timeout of a raw upload, as a Go duration", stringSchema},
+			{"selector", "query", "The label selector of a raw upload", stringSchema},
+			{"routing_key", "query", "The routing key of a raw upload", stringSchema},
+		},
+		request: []body{jsonBody(TaskRequest{}), binaryBody("*/*")},
+		responses: []response{
+			{http.StatusOK, "The task finished; success tells whether it succeeded", jsonBody(TaskResponse{})},
+			{http.StatusAccepted, "The task is scheduled or running", jsonBody(TaskResponse{})},
+			errorResponse(http.StatusBadRequest, "The request or payload is invalid"),
+			{http.StatusConflict, "The idempotency key belongs to another task, or the task was cancelled", jsonBody([]interface{}{TaskResponse{}, ErrorResponse{}})},
+			errorResponse(http.StatusUnprocessableEntity, "No worker supports the task type or matches the selector"),
+			{http.StatusBadGateway, "The worker failed the task's call", jsonBody(TaskResponse{})},
+			{http.StatusServiceUnavailable, "No worker can take the task now, or its worker was unreachable", jsonBody([]interface{}{TaskResponse{}, ErrorResponse{}})},
+			{http.StatusGatewayTimeout, "The task timed out", jsonBody(TaskResponse{})},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/tasks", id: "listTasks",
+		summary: "List the most recently submitted tasks, newest first",
+		params: []parameter{
+			{"limit", "query", "How many tasks to return, at most 1000; defaults to 50", schema{"type": "integer", "minimum": 1}},
+		},
+		responses: []response{
+			{http.StatusOK, "The tasks", jsonBody(listOf{"tasks", Task{}})},
+			errorResponse(http.StatusBadRequest, "The limit is not a positive integer"),
+		},
+	},
+	{
+		method: http.MethodGet, path: "/tasks/:task_id", id: "getTask",
+		summary: "Get the state and result of a task",
+		responses: []response{
+			{http.StatusOK, "The task", jsonBody(Task{})},
+			errorResponse(http.StatusNotFound, "The task does not exist"),
+		},
+	},
+	{
+		method: http.MethodGet, path: "/tasks/:task_id/result", id: "getTaskResult",
+		summary: "Download the raw result of a completed task, with its metadata in headers",
+		responses: []response{
+			{http.StatusOK, "The result, with its content type", binaryBody("*/*")},
+			errorResponse(http.StatusNotFound, "The task does not exist"),
+			errorResponse(http.StatusConflict, "The task has not completed"),
+		},
+	},
+	{
+		method: http.MethodPost, path: "/tasks/:task_id/cancel", id: "cancelTask",
+		summary: "Cancel a scheduled or running task",
+		responses: []response{
+			{http.StatusOK, "The cancelled task", jsonBody(Task{})},
+			errorResponse(http.StatusNotFound, "The task does not exist"),
+			errorResponse(http.StatusConflict, "The task already finished"),
+		},
+	},
+	{
+		method: http.MethodGet, path: "/events", id: "streamEvents",
+		summary: "Stream task state changes as server-sent events, each carrying the task",
+		responses: []response{
+			{http.StatusOK, "The event stream", body{contentType: "text/event-stream", value: stringSchema}},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/task-types", id: "listTaskTypes",
+		summary: "List the declared task types and their schemas",
+		responses: []response{
+			{http.StatusOK, "The task types", jsonBody(listOf{"task_types", TaskType{}})},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/task-types/:name", id: "getTaskType",
+		summary: "Get a task type",
+		responses: []response{
+			{http.StatusOK, "The task type", jsonBody(TaskType{})},
+			errorResponse(http.StatusNotFound, "The task type is not declared"),
+		},
+	},
+	{
+		method: http.MethodGet, path: "/status", id: "getWorkersStatus",
+		summary: "Get the live status of every worker",
+		responses: []response{
+			{http.StatusOK, "The workers' status", jsonBody(AllStatusResponse{})},
+			errorResponse(http.StatusBadGateway, "A worker failed the call"),
+			errorResponse(http.StatusServiceUnavailable, "A worker was unreachable"),
+		},
+	},
+	{
+		method: http.MethodGet, path: "/status/:worker_id", id: "getWorkerStatus",
+		summary: "Get the live status of a worker",
+		responses: []response{
+			{http.StatusOK, "The worker's status", jsonBody(StatusResponse{})},
+			errorResponse(http.StatusNotFound, "The worker does not exist"),
+			errorResponse(http.StatusBadGateway, "The worker failed the call"),
+			errorResponse(http.StatusServiceUnavailable, "The worker was unreachable"),
+		},
+	},
+	{
+		method: http.MethodPost, path: "/schedules", id: "createSchedule",
+		summary: "Create a recurring task schedule",
+		request: []body{jsonBody(ScheduleRequest{})},
+		responses: []response{
+			{http.StatusCreated, "The schedule", jsonBody(Schedule{})},
+			errorResponse(http.StatusBadRequest, "The schedule or its task is invalid"),
+		},
+	},
+	{
+		method: http.MethodGet, path: "/schedules", id: "listSchedules",
+		summary: "List schedules",
+		responses: []response{
+			{http.StatusOK, "The schedules", jsonBody(listOf{"schedules", Schedule{}})},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/schedules/:id", id: "getSchedule",
+		summary: "Get a schedule",
+		responses: []response{
+			{http.StatusOK, "The schedule", jsonBody(Schedule{})},
+			errorResponse(http.StatusNotFound, "The schedule does not exist"),
+		},
+	},
+	{
+		method: http.MethodDelete, path: "/schedules/:id", id: "deleteSchedule",
+		summary: "Delete a schedule",
+		responses: []response{
+			{status: http.StatusNoContent, description: "The schedule was deleted"},
+			errorResponse(http.StatusNotFound, "The schedule does not exist"),
+		},
+	},
+	{
+		method: http.MethodGet, path: "/schedules/:id/runs", id: "getScheduleRuns",
+		summary: "Get the run history of a schedule",
+		responses: []response{
+			{http.StatusOK, "The runs, oldest first", jsonBody(ScheduleRunsResponse{})},
+			errorResponse(http.StatusNotFound, "The schedule does not exist"),
+		},
+	},
+	{
+		method: http.MethodGet, path: "/workers", id: "listWorkers",
+		summary: "List the workers in the pool",
+		responses: []response{
+			{http.StatusOK, "The workers", jsonBody(listOf{"workers", WorkerResponse{}})},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/workers", id: "addWorker",
+		summary: "Add a worker; workers register themselves through it too",
+		request: []body{jsonBody(WorkerRequest{})},
+		responses: []response{
+			{http.StatusOK, "The worker was already in the pool at the same address", jsonBody(WorkerResponse{})},
+			{http.StatusCreated, "The worker was added", jsonBody(WorkerResponse{})},
+			errorResponse(http.StatusBadRequest, "The request is invalid"),
+			errorResponse(http.StatusConflict, "The ID or address belongs to another worker"),
+		},
+	},
+	{
+		method: http.MethodDelete, path: "/workers/:id", id: "removeWorker",
+		summary: "Remove a worker",
+		responses: []response{
+			{status: http.StatusNoContent, description: "The worker was removed"},
+			errorResponse(http.StatusNotFound, "The worker does not exist"),
+			errorResponse(http.StatusConflict, "The worker is the last one"),
+		},
+	},
+	{
+		method: http.MethodPost, path: "/workers/:id/cordon", id: "cordonWorker",
+		summary: "Stop sending new tasks to a worker",
+		responses: []response{
+			{http.StatusOK, "The worker", jsonBody(WorkerResponse{})},
+			errorResponse(http.StatusNotFound, "The worker does not exist"),
+		},
+	},
+	{
+		method: http.MethodPost, path: "/workers/:id/uncordon", id: "uncordonWorker",
+		summary: "Resume sending tasks to a worker",
+		responses: []response{
+			{http.StatusOK, "The worker", jsonBody(WorkerResponse{})},
+			errorResponse(http.StatusNotFound, "The worker does not exist"),
+		},
+	},
+	{
+		method: http.MethodPut, path: "/blobs", id: "putBlob",
+		summary: "Store a blob and get its digest",
+		request: []body{binaryBody(ContentTypeBinary)},
+		responses: []response{
+			{http.StatusOK, "The blob was already stored", jsonBody(Blob{})},
+			{http.StatusCreated, "The blob was stored", jsonBody(Blob{})},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/blobs/:digest", id: "getBlob",
+		summary: "Download a blob",
+		responses: []response{
+			{http.StatusOK, "The blob", binaryBody(ContentTypeBinary)},
+			errorResponse(http.StatusBadRequest, "The digest is invalid"),
+			errorResponse(http.StatusNotFound, "The blob does not exist"),
+		},
+	},
+	{
+		method: http.MethodHead, path: "/blobs/:digest", id: "headBlob",
+		summary: "Check that a blob exists",
+		responses: []response{
+			{status: http.StatusOK, description: "The blob exists"},
+			{status: http.StatusBadRequest, description: "The digest is invalid"},
+			{status: http.StatusNotFound, description: "The blob does not exist"},
+		},
+	},
+	{
+		method: http.MethodDelete, path: "/blobs/:digest", id: "deleteBlob",
+		summary: "Delete a blob",
+		responses: []response{
+			{status: http.StatusNoContent, description: "The blob was deleted"},
+			errorResponse(http.StatusBadRequest, "The digest is invalid"),
+			errorResponse(http.StatusNotFound, "The blob does not exist"),
+		},
+	},
+}
+
+// openAPI is the API's OpenAPI document, generated from apiOperations and
+// the types of their bodies, with the schemas request bodies are validated
+// against.
+type openAPI struct {
+	doc []byte
+	// bodies holds the schemas of JSON request bodies by method and route
+	bodies map[string]*jsonschema.Schema
+	// routes lists the documented routes, by method and path
+	routes map[string]bool
+}
+
+// newOpenAPI builds the document. A body schema that does not compile is a
+// programming error, so it panics.
+func newOpenAPI() *openAPI {
+	api := &openAPI{
+		bodies: make(map[string]*jsonschema.Schema),
+		routes: make(map[string]bool),
+	}
+	components := make(schema)
+	paths := make(map[string]schema)
+
+	for _, op := range apiOperations {
+		served := []string{apiPrefix + op.path, op.path}
+		if op.unversioned {
+			served = served[1:]
+		}
+
+		for _, path := range served {
+			key := op.method + " " + path
+			api.routes[key] = true
+			for _, req := range op.request {
+				if req.contentType != "application/json" {
+					continue
+				}
+				compiled, err := jsonschema.Compile(requestSchema(reflect.TypeOf(req.value)))
+				if err != nil {
+					panic(fmt.Sprintf("openapi: schema of %s: %v", key, err))
+				}
+				api.bodies[key] = compiled
+			}
+
+			deprecated := !op.unversioned && path == op.path
+			docPath := openAPIPath(path)
+			if paths[docPath] == nil {
+				paths[docPath] = make(schema)
+			}
+			paths[docPath][strings.ToLower(op.method)] = op.document(path, deprecated, components)
+		}
+	}
+
+	doc, err := json.Marshal(schema{
+		"openapi": "3.1.0",
+		"info": schema{
+			"title":       "Master-Worker System API",
+			"version":     "1.0.0",
+			"description": "Submits tasks to the master, which dispatches them to its workers. Paths outside " + apiPrefix + " are deprecated aliases.",
+		},
+		"paths":      paths,
+		"components": schema{"schemas": components},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("openapi: %v", err))
+	}
+	api.doc = doc
+	return api
+}
+
+// document renders the operation as served at path.
+func (op operation) document(path string, deprecated bool, components schema) schema {
+	id := op.id
+	if deprecated {
+		id += "Unversioned"
+	}
+	doc := schema{"operationId": id, "summary": op.summary}
+	if deprecated {
+		doc["deprecated"] = true
+	}
+
+	params := []schema{}
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := pathParam(segment); ok {
+			params = append(params, schema{"name": name, "in": "path", "required": true, "schema": stringSchema})
+		}
+	}
+	for _, param := range op.params {
+		params = append(params, schema{"name": param.name, "in": param.in, "description": param.description, "schema": param.schema})
+	}
+	if len(params) > 0 {
+		doc["parameters"] = params
+	}
+
+	if len(op.request) > 0 {
+		content := make(schema)
+		for _, req := range op.request {
+			if t := reflect.TypeOf(req.value); t.Kind() == reflect.Struct {
+				components[t.Name()] = requestSchema(t)
+			}
+			content[req.contentType] = schema{"schema": ref(req.value, components)}
+		}
+		doc["requestBody"] = schema{"required": true, "content": content}
+	}
+
+	responses := make(schema)
+	for _, resp := range op.responses {
+		entry := schema{"description": resp.description}
+		if resp.value != nil {
+			entry["content"] = schema{resp.contentType: schema{"schema": ref(resp.value, components)}}
+		}
+		responses[strconv.Itoa(resp.status)] = entry
+	}
+	doc["responses"] = responses
+	return doc
+}
+
+// ref returns the schema of a body value, adding the schemas of Go types to
+// the components and referring to them there.
+func ref(value interface{}, components schema) schema {
+	switch value := value.(type) {
+	case schema:
+		return value
+	case listOf:
+		return schema{
+			"type":       "object",
+			"properties": schema{value.key: schema{"type": "array", "items": ref(value.item, components)}},
+		}
+	case []interface{}:
+		alternatives := make([]schema, len(value))
+		for i, alternative := range value {
+			alternatives[i] = ref(alternative, components)
+		}
+		return schema{"oneOf": alternatives}
+	}
+
+	t := reflect.TypeOf(value)
+	if _, ok := components[t.Name()]; !ok {
+		components[t.Name()] = schemaOf(t)
+	}
+	return schema{"$ref": "#/components/schemas/" + t.Name()}
+}
+
+// openAPIPath turns a gin path into an OpenAPI one, e.g. /tasks/{task_id}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := pathParam(segment); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParam returns the name of the parameter a path segment stands for,
+// either :name or a catch-all *name.
+func pathParam(segment string) (string, bool) {
+	if name, ok := strings.CutPrefix(segment, ":"); ok {
+		return name, true
+	}
+	return strings.CutPrefix(segment, "*")
+}
+
+// knownSchemas describes the types whose JSON form is not their Go form.
+var knownSchemas = map[reflect.Type]schema{
+	reflect.TypeOf(time.Time{}):       {"type": "string", "format": "date-time"},
+	reflect.TypeOf(json.RawMessage{}): {"description": "Any JSON value"},
+	reflect.TypeOf(Selector{}):        {"type": "string", "description": "A label selector, e.g. zone in (a,b),gpu=true"},
+	reflect.TypeOf(TaskState("")):     {"type": "string", "enum": []TaskState{TaskStateScheduled, TaskStateRunning, TaskStateCompleted, TaskStateFailed, TaskStateCancelled, TaskStateTimedOut}},
+	reflect.TypeOf(ErrorCode("")):     {"type": "string", "enum": errorCodes()},
+}
+
+// extraProperties lists the fields types add in their MarshalJSON.
+var extraProperties = map[reflect.Type]schema{
+	reflect.TypeOf(Task{}): {
+		"payload":        schema{"description": "The payload, as JSON or text"},
+		"payload_base64": schema{"type": "string", "format": "byte"},
+		"result":         schema{"description": "The result, as JSON or text"},
+		"result_base64":  schema{"type": "string", "format": "byte"},
+		"timeout":        stringSchema,
+	},
+}
+
+func errorCodes() []ErrorCode {
+	codes := make([]ErrorCode, 0, len(codeStatuses))
+	for code := range codeStatuses {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
+
+// schemaOf generates the JSON Schema of a type from its JSON encoding.
+// Fields tagged binding:"required" are required, and pointers, maps and
+// slices may be null.
+func schemaOf(t reflect.Type) schema {
+	if known, ok := knownSchemas[t]; ok {
+		return copySchema(known)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(schemaOf(t.Elem()))
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return schema{"type": "number"}
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(schema{"type": "string", "format": "byte"})
+		}
+		return nullable(schema{"type": "array", "items": schemaOf(t.Elem())})
+	case reflect.Map:
+		return nullable(schema{"type": "object", "additionalProperties": schemaOf(t.Elem())})
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return schema{}
+	}
+}
+
+func structSchema(t reflect.Type) schema {
+	properties := make(schema)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Embedded structs contribute their fields
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := structSchema(field.Type)
+			for key, value := range embedded["properties"].(schema) {
+				properties[key] = value
+			}
+			if names, ok := embedded["required"].([]string); ok {
+				required = append(required, names...)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type)
+		if strings.Contains(field.Tag.Get("binding"), "required") {
+			required = append(required, name)
+		}
+	}
+	for key, value := range extraProperties[t] {
+		properties[key] = value
+	}
+
+	s := schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// requestSchema is the schema of a JSON request body. The handlers decode
+// bodies with encoding/json, which leaves a field given as null unset, so
+// any property that is not required may be null, as may array items and map
+// values.
+func requestSchema(t reflect.Type) schema {
+	return acceptNull(schemaOf(t))
+}
+
+// acceptNull returns a copy of s whose optional properties, items and map
+// values may be null.
+func acceptNull(s schema) schema {
+	s = copySchema(s)
+	if properties, ok := s["properties"].(schema); ok {
+		required := make(map[string]bool)
+		names, _ := s["required"].([]string)
+		for _, name := range names {
+			required[name] = true
+		}
+
+		copied := make(schema, len(properties))
+		for name, property := range properties {
+			property := acceptNull(property.(schema))
+			if !required[name] {
+				property = nullable(property)
+			}
+			copied[name] = property
+		}
+		s["properties"] = copied
+	}
+	if items, ok := s["items"].(schema); ok {
+		s["items"] = nullable(acceptNull(items))
+	}
+	if values, ok := s["additionalProperties"].(schema); ok {
+		s["additionalProperties"] = nullable(acceptNull(values))
+	}
+	return s
+}
+
+func nullable(s schema) schema {
+	if name, ok := s["type"].(string); ok {
+		s["type"] = []string{name, "null"}
+	}
+	return s
+}
+
+func copySchema(s schema) schema {
+	copied := make(schema, len(s))
+	for key, value := range s {
+		copied[key] = value
+	}
+	return copied
+}
+
+func (a *openAPI) serve(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", a.doc)
+}
+
+// validate checks JSON request bodies against the document before the
+// handler sees them.
+func (a *openAPI) validate(c *gin.Context) {
+	bodySchema, ok := a.bodies[c.Request.Method+" "+c.FullPath()]
+	if !ok || (c.ContentType() != "" && c.ContentType() != "application/json") {
+		c.Next()
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, CodeInvalidArgument, fmt.Errorf("failed to read request body: %w", err))
+		c.Abort()
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		respondError(c, CodeInvalidArgument, fmt.Errorf("invalid JSON body: %w", err))
+		c.Abort()
+		return
+	}
+	if err := bodySchema.Validate(value); err != nil {
+		respondError(c, CodeInvalidArgument, fmt.Errorf("request body does not match the API schema: %w", err))
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
+
+// mustMatch checks that the document covers exactly the routes served, and
+// panics when it does not.
+func (a *openAPI) mustMatch(routes gin.RoutesInfo) {
+	var mismatched []string
+	served := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		served[key] = true
+		if !a.routes[key] {
+			mismatched = append(mismatched, key+" is not documented")
+		}
+	}
+	for key := range a.routes {
+		if !served[key] {
+			mismatched = append(mismatched, key+" is documented but not served")
+		}
+	}
+
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		panic("openapi: " + strings.Join(mismatched, "; "))
+	}
+}
@@ -0,0 +1,73 @@
+package master_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/testcluster"
+)
+
+// TestValidateAcceptsOldRequestShapes replays request bodies the handlers
+// accepted before bodies were validated against the API schema, on the
+// deprecated unversioned paths and under /v1.
+func TestValidateAcceptsOldRequestShapes(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+	extra := c.StartWorker("worker-2")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{
+			"null optional task fields", http.MethodPost, "/tasks",
+			`{"task_type": "compute", "payload": "2+2", "payload_base64": null, "content_type": null,
+			  "metadata": null, "run_at": null, "delay": null, "timeout": null, "selector": null,
+			  "preferences": null, "routing_key": null}`,
+			http.StatusOK,
+		},
+		{
+			"null metadata value", http.MethodPost, "/tasks",
+			`{"task_type": "compute", "payload": "2+2", "metadata": {"owner": null}}`,
+			http.StatusOK,
+		},
+		{
+			"null selector in a schedule", http.MethodPost, "/schedules",
+			`{"name": null, "interval": "1h", "prevent_overlap": null,
+			  "task": {"task_type": "compute", "payload": "2+2", "selector": null}}`,
+			http.StatusCreated,
+		},
+		{
+			"null worker capabilities", http.MethodPost, "/workers",
+			`{"id": "worker-2", "url": "` + extra.Addr + `", "task_types": null, "labels": null}`,
+			http.StatusCreated,
+		},
+		{
+			"wrong type", http.MethodPost, "/tasks",
+			`{"task_type": "compute", "payload": "2+2", "selector": 5}`,
+			http.StatusBadRequest,
+		},
+		{
+			"null required field", http.MethodPost, "/tasks",
+			`{"task_type": null, "payload": "2+2"}`,
+			http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		for _, path := range []string{test.path, "/v1" + test.path} {
+			t.Run(test.name+" "+path, func(t *testing.T) {
+				rec := c.Do(test.method, path, json.RawMessage(test.body))
+				if rec.Code != test.want {
+					t.Fatalf("%s %s = %d %s, want %d", test.method, path, rec.Code, rec.Body, test.want)
+				}
+				if rec.Code == http.StatusCreated && path == "/workers" {
+					// Free the ID for the /v1 request
+					c.Do(http.MethodDelete, "/v1/workers/worker-2", nil)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package master
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+)
+
+// ResultCache keeps the results of deterministic task types by task type and
+// payload, so identical tasks are answered without reaching a worker.
+// Results expire after the TTL, and the least recently used ones are evicted
+// once the cached results exceed the size limit.
+type ResultCache struct {
+	ttl     time.Duration
+	maxSize int
+
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// order holds the entries, most recently used first
+	order *list.List
+}
+
+type cachedResult struct {
+	key     string
+	resp    *pb.TaskResponse
+	size    int
+	expires time.Time
+}
+
+func NewResultCache(ttl time.Duration, maxSize int) *ResultCache {
+	return &ResultCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// resultKey identifies a task's result: its type, content type and payload,
+// with blob payloads identified by their digest.
+func resultKey(task *Task) string {
+	hash := sha256.New()
+	hash.Write([]byte(task.TaskType))
+	hash.Write([]byte{0})
+	hash.Write([]byte(task.ContentType))
+	hash.Write([]byte{0})
+	if task.PayloadBlob != "" {
+		hash.Write([]byte(BlobRefPrefix + task.PayloadBlob))
+	} else {
+		hash.Write(task.Payload)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached response for a key, unless it has expired.
+func (c *ResultCache) Get(key string) (*pb.TaskResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cachedResult)
+	if time.Now().After(entry.expires) {
+		c.removeLocked(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.resp, true
+}
+
+// Put caches a successful response. Responses larger than the whole cache
+// are not kept.
+func (c *ResultCache) Put(key string, resp *pb.TaskResponse) {
+	size := len(key) + len(resp.Result) + len(resp.ContentType)
+	for k, v := range resp.Metadata {
+		size += len(k) + len(v)
+	}
+	if size > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeLocked(elem)
+	}
+	entry := &cachedResult{
+		key:     key,
+		resp:    resp,
+		size:    size,
+		expires: time.Now().Add(c.ttl),
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.size += size
+
+	for c.size > c.maxSize {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// Clear drops every cached result.
+func (c *ResultCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.size = 0
+}
+
+func (c *ResultCache) removeLocked(elem *list.Element) {
+	entry := c.order.Remove(elem).(*cachedResult)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
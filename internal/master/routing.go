@@ -0,0 +1,38 @@
+package master
+
+import "hash/fnv"
+
+// rendezvousPick chooses the worker with the highest rendezvous (highest
+// random weight) score for the key. The same key keeps landing on the same
+// worker, and when workers join or leave only the keys whose top-scoring
+// worker changed are moved.
+func rendezvousPick(key string, workers []*WorkerClient) *WorkerClient {
+	var best *WorkerClient
+	var bestScore uint64
+
+	for _, worker := range workers {
+		score := rendezvousScore(key, worker.id)
+		if best == nil || score > bestScore || (score == bestScore && worker.id < best.id) {
+			best = worker
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+func rendezvousScore(key, workerID string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(workerID))
+
+	// FNV alone spreads similar inputs poorly; finish with the splitmix64 mixer
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
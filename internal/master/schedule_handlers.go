@@ -0,0 +1,88 @@
+package master
+
+import (
+	"net/http"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleRequest struct {
+	Name           string      `json:"name"`
+	Cron           string      `json:"cron"`
+	Interval       string      `json:"interval"`
+	Task           TaskRequest `json:"task" binding:"required"`
+	PreventOverlap bool        `json:"prevent_overlap"`
+}
+
+type ScheduleRunsResponse struct {
+	ScheduleID string        `json:"schedule_id"`
+	Runs       []ScheduleRun `json:"runs"`
+}
+
+func setupScheduleRoutes(r *gin.RouterGroup, scheduler *Scheduler) {
+	// Create schedule endpoint
+	r.POST("/schedules", func(c *gin.Context) {
+		var req ScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, CodeInvalidArgument, err)
+			return
+		}
+
+		sched, err := scheduler.Add(Schedule{
+			Name:           req.Name,
+			Cron:           req.Cron,
+			Interval:       req.Interval,
+			Task:           req.Task,
+			PreventOverlap: req.PreventOverlap,
+		})
+		if err != nil {
+			logger.GetLogger().Warnf("Rejected schedule %q: %v", req.Name, err)
+			respondError(c, CodeInvalidArgument, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, sched)
+	})
+
+	// List schedules endpoint
+	r.GET("/schedules", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"schedules": scheduler.List()})
+	})
+
+	// Get specific schedule endpoint
+	r.GET("/schedules/:id", func(c *gin.Context) {
+		sched, err := scheduler.Get(c.Param("id"))
+		if err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, sched)
+	})
+
+	// Delete schedule endpoint
+	r.DELETE("/schedules/:id", func(c *gin.Context) {
+		if err := scheduler.Remove(c.Param("id")); err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	// Schedule run history endpoint
+	r.GET("/schedules/:id/runs", func(c *gin.Context) {
+		runs, err := scheduler.Runs(c.Param("id"))
+		if err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+
+		c.JSON(http.StatusOK, ScheduleRunsResponse{
+			ScheduleID: c.Param("id"),
+			Runs:       runs,
+		})
+	})
+}
@@ -0,0 +1,384 @@
+package master
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+type Schedule struct {
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	Cron           string      `json:"cron,omitempty"`
+	Interval       string      `json:"interval,omitempty"`
+	Task           TaskRequest `json:"task"`
+	PreventOverlap bool        `json:"prevent_overlap"`
+	CreatedAt      time.Time   `json:"created_at"`
+	NextRun        time.Time   `json:"next_run"`
+	LastRun        *time.Time  `json:"last_run,omitempty"`
+
+	timing  timing
+	running bool
+}
+
+type ScheduleRun struct {
+	TaskID     string     `json:"task_id,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Success    bool       `json:"success"`
+	Skipped    bool       `json:"skipped,omitempty"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// scheduleState is the on-disk representation of the scheduler.
+type scheduleState struct {
+	Schedules []*Schedule               `json:"schedules"`
+	Runs      map[string][]*ScheduleRun `json:"runs"`
+}
+
+// Scheduler submits task templates to the task manager on cron expressions
+// or fixed intervals and keeps a bounded run history per schedule.
+type Scheduler struct {
+	tasks        *TaskManager
+	storePath    string
+	historyLimit int
+
+	mu        sync.Mutex
+	schedules map[string]*Schedule
+	runs      map[string][]*ScheduleRun
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewScheduler(tasks *TaskManager, cfg *config.Config) (*Scheduler, error) {
+	s := &Scheduler{
+		tasks:        tasks,
+		storePath:    cfg.Scheduler.StorePath,
+		historyLimit: cfg.GetScheduleHistoryLimit(),
+		schedules:    make(map[string]*Schedule),
+		runs:         make(map[string][]*ScheduleRun),
+		stop:         make(chan struct{}),
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Start runs the scheduling loop until Stop is called.
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				s.runDue(now)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the scheduling loop. Runs already dispatched are not waited
+// for, since their tasks may take as long as their timeout allows.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Add validates and registers a new schedule, returning the stored copy.
+func (s *Scheduler) Add(sched Schedule) (*Schedule, error) {
+	t, err := parseTiming(sched.Cron, sched.Interval)
+	if err != nil {
+		return nil, err
+	}
+	if sched.Task.TaskType == "" {
+		return nil, fmt.Errorf("task.task_type is required")
+	}
+	if _, _, _, err := s.tasks.payload(sched.Task); err != nil {
+		return nil, fmt.Errorf("task: %w", err)
+	}
+	if _, err := s.tasks.timeout(sched.Task); err != nil {
+		return nil, fmt.Errorf("task: %w", err)
+	}
+
+	now := time.Now()
+	sched.ID = uuid.New().String()
+	sched.CreatedAt = now
+	sched.NextRun = t.Next(now)
+	sched.LastRun = nil
+	sched.timing = t
+	if sched.NextRun.IsZero() {
+		return nil, fmt.Errorf("schedule never fires")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.schedules[sched.ID] = &sched
+	if err := s.saveLocked(); err != nil {
+		delete(s.schedules, sched.ID)
+		return nil, err
+	}
+
+	logger.GetLogger().Infof("Added schedule %s (%s), next run at %s", sched.ID, sched.Name, sched.NextRun.Format(time.RFC3339))
+
+	copied := sched
+	return &copied, nil
+}
+
+func (s *Scheduler) Get(id string) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sched, ok := s.schedules[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	copied := *sched
+	return &copied, nil
+}
+
+func (s *Scheduler) List() []*Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*Schedule, 0, len(s.schedules))
+	for _, sched := range s.schedules {
+		copied := *sched
+		list = append(list, &copied)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+func (s *Scheduler) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.schedules[id]; !ok {
+		return ErrScheduleNotFound
+	}
+	delete(s.schedules, id)
+	delete(s.runs, id)
+
+	logger.GetLogger().Infof("Removed schedule %s", id)
+	return s.saveLocked()
+}
+
+// Runs returns the run history of a schedule, most recent first.
+func (s *Scheduler) Runs(id string) ([]ScheduleRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.schedules[id]; !ok {
+		return nil, ErrScheduleNotFound
+	}
+
+	history := s.runs[id]
+	runs := make([]ScheduleRun, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		runs = append(runs, *history[i])
+	}
+	return runs, nil
+}
+
+func (s *Scheduler) runDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for _, sched := range s.schedules {
+		if now.Before(sched.NextRun) {
+			continue
+		}
+
+		started := now
+		sched.LastRun = &started
+		sched.NextRun = sched.timing.Next(now)
+		changed = true
+
+		if sched.running && sched.PreventOverlap {
+			logger.GetLogger().Warnf("Skipping run of schedule %s: previous run still in progress", sched.ID)
+			s.recordLocked(sched.ID, &ScheduleRun{
+				StartedAt: now,
+				Skipped:   true,
+				Error:     "previous run still in progress",
+			})
+			continue
+		}
+
+		run := &ScheduleRun{
+			TaskID:    uuid.New().String(),
+			StartedAt: now,
+		}
+		s.recordLocked(sched.ID, run)
+		sched.running = true
+
+		go s.execute(sched, run)
+	}
+
+	if changed {
+		if err := s.saveLocked(); err != nil {
+			logger.GetLogger().Errorf("Failed to persist schedules: %v", err)
+		}
+	}
+}
+
+func (s *Scheduler) execute(sched *Schedule, run *ScheduleRun) {
+	logger.GetLogger().Infof("Schedule %s dispatching task %s of type %s", sched.ID, run.TaskID, sched.Task.TaskType)
+
+	task, err := s.tasks.Submit(run.TaskID, sched.Task, time.Time{})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	sched.running = false
+
+	if err != nil {
+		logger.GetLogger().Errorf("Scheduled task %s of schedule %s failed: %v", run.TaskID, sched.ID, err)
+	}
+	if task == nil {
+		// The task could not even be recorded
+		run.Error = err.Error()
+	} else {
+		run.Success = task.Success
+		run.Result = describeData(task.Result, task.ResultContentType)
+		run.Error = task.Error
+	}
+
+	if err := s.saveLocked(); err != nil {
+		logger.GetLogger().Errorf("Failed to persist schedules: %v", err)
+	}
+}
+
+func (s *Scheduler) recordLocked(id string, run *ScheduleRun) {
+	history := append(s.runs[id], run)
+	if len(history) > s.historyLimit {
+		history = history[len(history)-s.historyLimit:]
+	}
+	s.runs[id] = history
+}
+
+func (s *Scheduler) load() error {
+	if s.storePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.storePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schedule store: %w", err)
+	}
+
+	var state scheduleState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse schedule store: %w", err)
+	}
+
+	now := time.Now()
+	for _, sched := range state.Schedules {
+		t, err := parseTiming(sched.Cron, sched.Interval)
+		if err != nil {
+			return fmt.Errorf("schedule %s: %w", sched.ID, err)
+		}
+		sched.timing = t
+		// Runs missed while the master was down are not replayed
+		if sched.NextRun.Before(now) {
+			sched.NextRun = t.Next(now)
+		}
+		s.schedules[sched.ID] = sched
+	}
+	for id, runs := range state.Runs {
+		if _, ok := s.schedules[id]; ok {
+			s.runs[id] = runs
+		}
+	}
+
+	logger.GetLogger().Infof("Loaded %d schedules from %s", len(s.schedules), s.storePath)
+	return nil
+}
+
+func (s *Scheduler) saveLocked() error {
+	if s.storePath == "" {
+		return nil
+	}
+
+	state := scheduleState{
+		Schedules: make([]*Schedule, 0, len(s.schedules)),
+		Runs:      s.runs,
+	}
+	for _, sched := range s.schedules {
+		state.Schedules = append(state.Schedules, sched)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schedules: %w", err)
+	}
+
+	if dir := filepath.Dir(s.storePath); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create schedule store directory: %w", err)
+		}
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated store
+	tmp := s.storePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write schedule store: %w", err)
+	}
+	if err := os.Rename(tmp, s.storePath); err != nil {
+		return fmt.Errorf("failed to write schedule store: %w", err)
+	}
+
+	return nil
+}
+
+func parseTiming(cronExpr, interval string) (timing, error) {
+	switch {
+	case cronExpr != "" && interval != "":
+		return nil, fmt.Errorf("only one of cron or interval may be set")
+	case cronExpr != "":
+		return ParseCron(cronExpr)
+	case interval != "":
+		every, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %w", interval, err)
+		}
+		if every < time.Second {
+			return nil, fmt.Errorf("interval must be at least 1s")
+		}
+		return intervalSchedule{every: every}, nil
+	default:
+		return nil, fmt.Errorf("one of cron or interval is required")
+	}
+}
@@ -0,0 +1,47 @@
+package master_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/master"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/testcluster"
+)
+
+func TestSchedulerStopDoesNotWaitForRuns(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+	worker := c.Worker("worker-1")
+	worker.SetLatency(time.Minute)
+
+	scheduler, err := master.NewScheduler(c.Tasks, c.Pool.Config())
+	if err != nil {
+		t.Fatalf("NewScheduler: %v", err)
+	}
+	if _, err := scheduler.Add(master.Schedule{
+		Interval: "1s",
+		Task:     master.TaskRequest{TaskType: "compute", Payload: json.RawMessage(`"2+2"`)},
+	}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	scheduler.Start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for worker.Calls() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("schedule never ran")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop waited for a running task")
+	}
+}
@@ -0,0 +1,216 @@
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type selectorOp string
+
+const (
+	opEquals       selectorOp = "="
+	opNotEquals    selectorOp = "!="
+	opIn           selectorOp = "in"
+	opNotIn        selectorOp = "notin"
+	opExists       selectorOp = "exists"
+	opDoesNotExist selectorOp = "!"
+)
+
+type requirement struct {
+	key    string
+	op     selectorOp
+	values []string
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+
+	switch r.op {
+	case opExists:
+		return ok
+	case opDoesNotExist:
+		return !ok
+	case opEquals:
+		return ok && value == r.values[0]
+	case opNotEquals:
+		return !ok || value != r.values[0]
+	case opIn:
+		return ok && contains(r.values, value)
+	case opNotIn:
+		return !ok || !contains(r.values, value)
+	}
+	return false
+}
+
+// Selector is a label selector in the Kubernetes style: a comma-separated
+// list of requirements such as "zone=a", "tier!=batch", "zone in (a,b)",
+// "zone notin (c)", "gpu" or "!gpu", all of which must hold. It is read from
+// and written to JSON as its string form.
+type Selector struct {
+	raw          string
+	requirements []requirement
+}
+
+func ParseSelector(expr string) (Selector, error) {
+	sel := Selector{raw: strings.TrimSpace(expr)}
+	if sel.raw == "" {
+		return sel, nil
+	}
+
+	for _, part := range splitRequirements(sel.raw) {
+		req, err := parseRequirement(part)
+		if err != nil {
+			return Selector{}, fmt.Errorf("invalid selector %q: %w", expr, err)
+		}
+		sel.requirements = append(sel.requirements, req)
+	}
+
+	return sel, nil
+}
+
+// Matches reports whether the labels satisfy every requirement. An empty
+// selector matches everything.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s.requirements {
+		if !req.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+func (s Selector) String() string {
+	return s.raw
+}
+
+func (s Selector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.raw)
+}
+
+func (s *Selector) UnmarshalJSON(data []byte) error {
+	var expr string
+	if err := json.Unmarshal(data, &expr); err != nil {
+		return fmt.Errorf("selector must be a string")
+	}
+
+	parsed, err := ParseSelector(expr)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// splitRequirements splits on commas that are not inside a value set.
+func splitRequirements(expr string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range expr {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(expr[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(expr[start:]))
+}
+
+func parseRequirement(part string) (requirement, error) {
+	if part == "" {
+		return requirement{}, fmt.Errorf("empty requirement")
+	}
+
+	if strings.HasPrefix(part, "!") && !strings.Contains(part, "=") {
+		key := strings.TrimSpace(part[1:])
+		if key == "" {
+			return requirement{}, fmt.Errorf("missing key in %q", part)
+		}
+		return requirement{key: key, op: opDoesNotExist}, nil
+	}
+
+	fields := strings.Fields(part)
+	if len(fields) >= 2 && (fields[1] == "in" || fields[1] == "notin") {
+		key := fields[0]
+		set := strings.TrimSpace(part[len(key):])
+		set = strings.TrimSpace(set[len(fields[1]):])
+		if !strings.HasPrefix(set, "(") || !strings.HasSuffix(set, ")") {
+			return requirement{}, fmt.Errorf("values of %q must be enclosed in parentheses", part)
+		}
+
+		var values []string
+		for _, v := range strings.Split(set[1:len(set)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return requirement{}, fmt.Errorf("empty value set in %q", part)
+		}
+		return requirement{key: key, op: selectorOp(fields[1]), values: values}, nil
+	}
+
+	for _, op := range []string{"!=", "==", "="} {
+		if key, value, ok := strings.Cut(part, op); ok {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if key == "" {
+				return requirement{}, fmt.Errorf("missing key in %q", part)
+			}
+			if op == "!=" {
+				return requirement{key: key, op: opNotEquals, values: []string{value}}, nil
+			}
+			return requirement{key: key, op: opEquals, values: []string{value}}, nil
+		}
+	}
+
+	if strings.ContainsAny(part, " ()") {
+		return requirement{}, fmt.Errorf("cannot parse %q", part)
+	}
+	return requirement{key: part, op: opExists}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Preference is a soft placement rule. Workers matching the selector gain
+// the weight; a negative weight expresses anti-affinity.
+type Preference struct {
+	Selector Selector `json:"selector"`
+	Weight   int      `json:"weight"`
+}
+
+// Placement constrains and ranks the workers a task may be dispatched to.
+// Tasks sharing a RoutingKey are sent to the same worker among the eligible
+// ones for as long as it stays eligible.
+type Placement struct {
+	Selector    Selector     `json:"selector"`
+	Preferences []Preference `json:"preferences,omitempty"`
+	RoutingKey  string       `json:"routing_key,omitempty"`
+}
+
+// score sums the weights of the preferences the labels satisfy.
+func (p Placement) score(labels map[string]string) int {
+	total := 0
+	for _, pref := range p.Preferences {
+		if pref.Selector.Matches(labels) {
+			total += pref.Weight
+		}
+	}
+	return total
+}
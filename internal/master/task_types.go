@@ -0,0 +1,180 @@
+package master
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/jsonschema"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/mediatype"
+)
+
+// TaskType is an entry of the task type catalog.
+type TaskType struct {
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description,omitempty"`
+	PayloadSchema map[string]interface{} `json:"payload_schema,omitempty"`
+	ResultSchema  map[string]interface{} `json:"result_schema,omitempty"`
+	Deterministic bool                   `json:"deterministic,omitempty"`
+	Timeout       string                 `json:"timeout,omitempty"`
+	MaxTimeout    string                 `json:"max_timeout,omitempty"`
+
+	payload    *jsonschema.Schema
+	result     *jsonschema.Schema
+	timeout    time.Duration
+	maxTimeout time.Duration
+}
+
+// SchemaError is a payload or result that does not match its task type's
+// schema. Errors hold the violations, with paths under payload or result.
+type SchemaError struct {
+	TaskType string
+	Errors   jsonschema.Errors
+
+	// root is what was checked, payload or result
+	root string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s does not match the schema of task type %s: %v", e.root, e.TaskType, e.Errors)
+}
+
+// TaskTypes is the catalog of task types declared in the configuration.
+type TaskTypes struct {
+	mu    sync.RWMutex
+	types map[string]*TaskType
+}
+
+func NewTaskTypes(cfgs []config.TaskTypeConfig) (*TaskTypes, error) {
+	c := &TaskTypes{}
+	if err := c.Update(cfgs); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Update replaces the catalog, keeping the old one when a schema does not
+// compile.
+func (c *TaskTypes) Update(cfgs []config.TaskTypeConfig) error {
+	types := make(map[string]*TaskType, len(cfgs))
+	for _, cfg := range cfgs {
+		taskType := &TaskType{
+			Name:          cfg.Name,
+			Description:   cfg.Description,
+			PayloadSchema: cfg.PayloadSchema,
+			ResultSchema:  cfg.ResultSchema,
+			Deterministic: cfg.Deterministic,
+			Timeout:       cfg.Timeout,
+			MaxTimeout:    cfg.MaxTimeout,
+		}
+
+		var err error
+		if cfg.PayloadSchema != nil {
+			if taskType.payload, err = jsonschema.Compile(cfg.PayloadSchema); err != nil {
+				return fmt.Errorf("task type %s: payload_schema: %w", cfg.Name, err)
+			}
+		}
+		if cfg.ResultSchema != nil {
+			if taskType.result, err = jsonschema.Compile(cfg.ResultSchema); err != nil {
+				return fmt.Errorf("task type %s: result_schema: %w", cfg.Name, err)
+			}
+		}
+		if cfg.Timeout != "" {
+			if taskType.timeout, err = time.ParseDuration(cfg.Timeout); err != nil {
+				return fmt.Errorf("task type %s: timeout: %w", cfg.Name, err)
+			}
+		}
+		if cfg.MaxTimeout != "" {
+			if taskType.maxTimeout, err = time.ParseDuration(cfg.MaxTimeout); err != nil {
+				return fmt.Errorf("task type %s: max_timeout: %w", cfg.Name, err)
+			}
+		}
+		types[cfg.Name] = taskType
+	}
+
+	c.mu.Lock()
+	c.types = types
+	c.mu.Unlock()
+	return nil
+}
+
+// List returns the declared task types by name.
+func (c *TaskTypes) List() []*TaskType {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	types := make([]*TaskType, 0, len(c.types))
+	for _, taskType := range c.types {
+		types = append(types, taskType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	return types
+}
+
+func (c *TaskTypes) Get(name string) (*TaskType, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	taskType, ok := c.types[name]
+	return taskType, ok
+}
+
+// Deterministic reports whether results of the task type can be cached.
+func (c *TaskTypes) Deterministic(taskType string) bool {
+	t, ok := c.Get(taskType)
+	return ok && t.Deterministic
+}
+
+// Timeouts returns the default and maximum timeout of a task type, which are
+// 0 when not configured.
+func (c *TaskTypes) Timeouts(taskType string) (time.Duration, time.Duration) {
+	t, ok := c.Get(taskType)
+	if !ok {
+		return 0, 0
+	}
+	return t.timeout, t.maxTimeout
+}
+
+// ValidatePayload checks a payload against its task type's schema. Task types
+// without one accept any payload.
+func (c *TaskTypes) ValidatePayload(taskType string, data []byte, contentType string) error {
+	t, ok := c.Get(taskType)
+	if !ok || t.payload == nil {
+		return nil
+	}
+	return t.check(t.payload, "payload", data, contentType)
+}
+
+// ValidateResult checks a worker's result against its task type's schema.
+func (c *TaskTypes) ValidateResult(taskType string, data []byte, contentType string) error {
+	t, ok := c.Get(taskType)
+	if !ok || t.result == nil {
+		return nil
+	}
+	return t.check(t.result, "result", data, contentType)
+}
+
+// check validates JSON content as decoded, and text as a JSON string. Binary
+// content cannot match a schema.
+func (t *TaskType) check(schema *jsonschema.Schema, root string, data []byte, contentType string) error {
+	var value interface{}
+	switch {
+	case mediatype.IsJSON(contentType):
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("%s is not valid JSON: %w", root, err)
+		}
+	case utf8.Valid(data):
+		value = string(data)
+	default:
+		return &SchemaError{TaskType: t.Name, Errors: jsonschema.Errors{{Path: root, Message: "must be JSON or text, not " + contentType}}, root: root}
+	}
+
+	if err := schema.Validate(value); err != nil {
+		return &SchemaError{TaskType: t.Name, Errors: err.(jsonschema.Errors).Prefix(root), root: root}
+	}
+	return nil
+}
@@ -0,0 +1,790 @@
+package master
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTaskNotFound = errors.New("task not found")
+	ErrTaskFinished = errors.New("task already finished")
+	// ErrInvalidTask wraps problems with a task request itself
+	ErrInvalidTask = errors.New("invalid task")
+	// ErrIdempotencyKeyReused is returned when a request reuses the
+	// idempotency key of a different task
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different task")
+)
+
+type TaskState string
+
+const (
+	TaskStateScheduled TaskState = "scheduled"
+	TaskStateRunning   TaskState = "running"
+	TaskStateCompleted TaskState = "completed"
+	TaskStateFailed    TaskState = "failed"
+	TaskStateCancelled TaskState = "cancelled"
+	// TaskStateTimedOut is a task whose worker did not answer within its
+	// timeout
+	TaskStateTimedOut TaskState = "timed_out"
+)
+
+// Finished reports whether the state is final.
+func (s TaskState) Finished() bool {
+	return s == TaskStateCompleted || s == TaskStateFailed || s == TaskStateCancelled || s == TaskStateTimedOut
+}
+
+type Task struct {
+	ID       string `json:"task_id"`
+	TaskType string `json:"task_type"`
+	Payload  []byte `json:"-"`
+	// PayloadBlob is the digest of the blob holding the payload, which
+	// workers fetch themselves, when the payload was given as a blob
+	// reference
+	PayloadBlob string            `json:"payload_blob,omitempty"`
+	ContentType string            `json:"content_type"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	// Timeout is how long the worker is given once the task is dispatched
+	Timeout time.Duration `json:"-"`
+	Placement
+	State   TaskState `json:"state"`
+	Success bool      `json:"success"`
+	Result  []byte    `json:"-"`
+	// Cached is set when the result was reused from an identical earlier
+	// task instead of being computed by a worker
+	Cached            bool              `json:"cached,omitempty"`
+	ResultContentType string            `json:"result_content_type,omitempty"`
+	ResultMetadata    map[string]string `json:"result_metadata,omitempty"`
+	Error             string            `json:"error,omitempty"`
+	// ErrorCode tells why an unsuccessful task failed
+	ErrorCode  ErrorCode  `json:"error_code,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RunAt      *time.Time `json:"run_at,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// cancel aborts the call to the worker while the task is running
+	cancel context.CancelFunc
+	// idempotencyKey is the key the task was submitted under, forgotten
+	// along with the task
+	idempotencyKey string
+}
+
+// MarshalJSON renders JSON payloads and results as JSON, others as text when
+// they are valid UTF-8, and the rest as base64 in payload_base64 and
+// result_base64. The timeout is written as a duration, e.g. 30s.
+func (t Task) MarshalJSON() ([]byte, error) {
+	type task Task
+	payload, payloadBase64 := encodeData(t.Payload, t.ContentType)
+	result, resultBase64 := encodeData(t.Result, t.ResultContentType)
+
+	return json.Marshal(struct {
+		task
+		Payload       json.RawMessage `json:"payload,omitempty"`
+		PayloadBase64 string          `json:"payload_base64,omitempty"`
+		Result        json.RawMessage `json:"result,omitempty"`
+		ResultBase64  string          `json:"result_base64,omitempty"`
+		Timeout       string          `json:"timeout,omitempty"`
+	}{
+		task:          task(t),
+		Payload:       payload,
+		PayloadBase64: payloadBase64,
+		Result:        result,
+		ResultBase64:  resultBase64,
+		Timeout:       formatDuration(t.Timeout),
+	})
+}
+
+// TaskManager records every submitted task and dispatches it through the
+// worker pool, either immediately or once its run time is reached.
+type TaskManager struct {
+	pool  *WorkerPool
+	queue *DelayQueue
+	types *TaskTypes
+	blobs BlobStore
+	cache *ResultCache
+
+	mu          sync.RWMutex
+	tasks       map[string]*Task
+	watchers    map[string][]chan *Task
+	subscribers []chan *Task
+
+	// finished lists finished tasks in the order they finished, for
+	// eviction; evict is signalled when there are too many
+	finished []*Task
+	evict    chan struct{}
+	done     chan struct{}
+
+	// wg tracks tasks dispatched in the background by start
+	wg sync.WaitGroup
+
+	// idempotency guards keys, which maps idempotency keys to task IDs
+	idempotency sync.Mutex
+	keys        map[string]string
+}
+
+// evictInterval is how often finished tasks past their retention are
+// evicted.
+const evictInterval = 10 * time.Second
+
+func NewTaskManager(pool *WorkerPool, blobs BlobStore) *TaskManager {
+	// The configuration was validated, so its schemas compile
+	types, err := NewTaskTypes(pool.Config().TaskTypes)
+	if err != nil {
+		logger.GetLogger().Errorf("Failed to load task types: %v", err)
+		types = &TaskTypes{}
+	}
+
+	m := &TaskManager{
+		pool:     pool,
+		types:    types,
+		blobs:    blobs,
+		cache:    NewResultCache(pool.Config().GetResultCacheTTL(), pool.Config().GetResultCacheSize()),
+		tasks:    make(map[string]*Task),
+		watchers: make(map[string][]chan *Task),
+		keys:     make(map[string]string),
+		evict:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	m.queue = NewDelayQueue(func(task *Task) {
+		m.execute(context.Background(), task)
+	})
+	go m.evictFinished()
+	return m
+}
+
+// SubmitRequest is the dispatch path shared by the REST and gRPC APIs: it
+// validates the request, checks that a worker can take the task and submits
+// it under a new ID. With wait set, a task that is due now is run
+// synchronously as with Submit; otherwise it runs in the background and can
+// be followed with Get or Watch. A request carrying an idempotency key that
+// was seen before returns the task submitted the first time instead. When
+// waiting, ctx ending cancels the task.
+func (m *TaskManager) SubmitRequest(ctx context.Context, req TaskRequest, wait bool) (*Task, error) {
+	task, err := m.newTask(req)
+	if err != nil {
+		return nil, err
+	}
+
+	runAt, err := req.runTime()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+
+	// A cached result needs no worker, so it is returned even when no
+	// worker could take the task
+	if !m.hasCachedResult(task) {
+		if err := m.pool.CanPlace(task.TaskType, task.Placement); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.IdempotencyKey != "" {
+		// Held until the task is recorded so concurrent retries of one
+		// request cannot both submit it
+		m.idempotency.Lock()
+		if taskID, ok := m.keys[req.IdempotencyKey]; ok {
+			m.idempotency.Unlock()
+			return m.replay(taskID, task, req.IdempotencyKey)
+		}
+	}
+
+	// Generate task ID
+	task.ID = uuid.New().String()
+	task.idempotencyKey = req.IdempotencyKey
+	logger.GetLogger().Infof("Received task: %s, Type: %s, Payload: %s", task.ID, task.TaskType, describeData(task.Payload, task.ContentType))
+
+	// Record the task, holding it until its run time when that is later
+	due := m.record(task, runAt)
+	if req.IdempotencyKey != "" {
+		m.keys[req.IdempotencyKey] = task.ID
+		m.idempotency.Unlock()
+	}
+
+	switch {
+	case !due:
+		return m.snapshot(task), nil
+	case !wait:
+		m.start(task)
+		return m.snapshot(task), nil
+	}
+
+	// Process task via worker
+	err = m.execute(ctx, task)
+
+	return m.snapshot(task), err
+}
+
+// replay returns the task first submitted under an idempotency key, provided
+// the retried request describes the same task.
+func (m *TaskManager) replay(taskID string, retried *Task, key string) (*Task, error) {
+	task, err := m.Get(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.TaskType != retried.TaskType || !bytes.Equal(task.Payload, retried.Payload) || task.PayloadBlob != retried.PayloadBlob || task.ContentType != retried.ContentType {
+		return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, key)
+	}
+
+	logger.GetLogger().Infof("Replaying task %s for idempotency key %s", taskID, key)
+	return task, nil
+}
+
+// TaskTypes returns the catalog payloads and results are validated against.
+func (m *TaskManager) TaskTypes() *TaskTypes {
+	return m.types
+}
+
+// UpdateTaskTypes replaces the task type catalog, keeping the old one when a
+// schema does not compile. Cached results are dropped, as they were checked
+// against the old result schemas.
+func (m *TaskManager) UpdateTaskTypes(cfgs []config.TaskTypeConfig) error {
+	if err := m.types.Update(cfgs); err != nil {
+		return err
+	}
+	m.cache.Clear()
+	return nil
+}
+
+// hasCachedResult reports whether a task would be answered from the result
+// cache.
+func (m *TaskManager) hasCachedResult(task *Task) bool {
+	if !m.types.Deterministic(task.TaskType) {
+		return false
+	}
+	_, ok := m.cache.Get(resultKey(task))
+	return ok
+}
+
+// Blobs returns the store blob payloads are referenced from.
+func (m *TaskManager) Blobs() BlobStore {
+	return m.blobs
+}
+
+// newTask builds an unrecorded task from a request, without an ID, checking
+// the payload against its task type's schema.
+func (m *TaskManager) newTask(req TaskRequest) (*Task, error) {
+	if req.TaskType == "" {
+		return nil, fmt.Errorf("%w: task_type is required", ErrInvalidTask)
+	}
+
+	payload, contentType, blob, err := m.payload(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTask, err)
+	}
+	timeout, err := m.timeout(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTask, err)
+	}
+
+	return &Task{
+		TaskType:    req.TaskType,
+		Payload:     payload,
+		PayloadBlob: blob,
+		ContentType: contentType,
+		Metadata:    req.Metadata,
+		Timeout:     timeout,
+		Placement:   req.placement(),
+	}, nil
+}
+
+// payload resolves a request's payload and checks it against its task
+// type's schema. A payload referring to a blob is returned as the blob's
+// digest, with no data; the blob must exist, and is read only to be checked.
+func (m *TaskManager) payload(req TaskRequest) ([]byte, string, string, error) {
+	digest, isBlob, err := req.blobRef()
+	if err != nil {
+		return nil, "", "", err
+	}
+	if !isBlob {
+		payload, contentType, err := req.content()
+		if err != nil {
+			return nil, "", "", err
+		}
+		if err := m.types.ValidatePayload(req.TaskType, payload, contentType); err != nil {
+			return nil, "", "", err
+		}
+		return payload, contentType, "", nil
+	}
+
+	contentType := contentTypeOr(req.ContentType, ContentTypeBinary)
+	if t, ok := m.types.Get(req.TaskType); ok && t.payload != nil {
+		data, err := readBlob(m.blobs, digest)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if err := m.types.ValidatePayload(req.TaskType, data, contentType); err != nil {
+			return nil, "", "", err
+		}
+	} else if _, err := m.blobs.Stat(digest); err != nil {
+		return nil, "", "", err
+	}
+	return nil, contentType, digest, nil
+}
+
+// timeout resolves how long a task may run: the requested timeout, else its
+// task type's default, else grpc.timeout. The type's maximum rejects longer
+// requested timeouts and caps grpc.timeout.
+func (m *TaskManager) timeout(req TaskRequest) (time.Duration, error) {
+	requested, err := req.requestedTimeout()
+	if err != nil {
+		return 0, err
+	}
+
+	byDefault, maximum := m.types.Timeouts(req.TaskType)
+	switch {
+	case requested > 0 && maximum > 0 && requested > maximum:
+		return 0, fmt.Errorf("timeout %s exceeds the maximum of %s for task type %s", requested, maximum, req.TaskType)
+	case requested > 0:
+		return requested, nil
+	case byDefault > 0:
+		return byDefault, nil
+	}
+
+	timeout := m.pool.Config().GetGRPCTimeout()
+	if maximum > 0 && timeout > maximum {
+		timeout = maximum
+	}
+	return timeout, nil
+}
+
+// Submit records a task under the given ID and runs it. Tasks with a run time
+// in the future are held in the delay queue and returned in the scheduled
+// state; all others are dispatched synchronously and returned once finished,
+// along with any error from reaching a worker.
+func (m *TaskManager) Submit(taskID string, req TaskRequest, runAt time.Time) (*Task, error) {
+	task, err := m.newTask(req)
+	if err != nil {
+		return nil, err
+	}
+	task.ID = taskID
+
+	if due := m.record(task, runAt); !due {
+		return m.snapshot(task), nil
+	}
+
+	err = m.execute(context.Background(), task)
+
+	return m.snapshot(task), err
+}
+
+// start dispatches a task in the background. The task is marked running
+// before returning so callers see it as such.
+func (m *TaskManager) start(task *Task) {
+	if m.markRunning(task) {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.execute(context.Background(), task)
+		}()
+	}
+}
+
+// record stores a new task and queues it when its run time is in the future.
+// It reports whether the task is due now.
+func (m *TaskManager) record(task *Task, runAt time.Time) bool {
+	now := time.Now()
+	task.CreatedAt = now
+
+	if runAt.After(now) {
+		task.State = TaskStateScheduled
+		task.RunAt = &runAt
+
+		m.mu.Lock()
+		m.tasks[task.ID] = task
+		m.notifyLocked(task)
+		m.mu.Unlock()
+
+		logger.GetLogger().Infof("Task %s scheduled to run at %s", task.ID, runAt.Format(time.RFC3339))
+		m.queue.Push(task, runAt)
+
+		return false
+	}
+
+	m.mu.Lock()
+	m.tasks[task.ID] = task
+	m.mu.Unlock()
+
+	return true
+}
+
+func (m *TaskManager) Get(taskID string) (*Task, error) {
+	m.mu.RLock()
+	task, ok := m.tasks[taskID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return m.snapshot(task), nil
+}
+
+// List returns up to limit of the most recently submitted tasks, newest
+// first.
+func (m *TaskManager) List(limit int) []*Task {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tasks := make([]*Task, 0, len(m.tasks))
+	for _, task := range m.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	for i, task := range tasks {
+		copied := *task
+		tasks[i] = &copied
+	}
+	return tasks
+}
+
+// Cancel stops a task. A scheduled task is never dispatched; for a running
+// one the call to its worker is aborted. Finished tasks cannot be cancelled.
+func (m *TaskManager) Cancel(taskID string) (*Task, error) {
+	m.mu.Lock()
+	task, ok := m.tasks[taskID]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrTaskNotFound
+	}
+	if task.State.Finished() {
+		m.mu.Unlock()
+		return nil, ErrTaskFinished
+	}
+
+	wasScheduled := task.State == TaskStateScheduled
+	if task.cancel != nil {
+		task.cancel()
+	}
+	finished := time.Now()
+	task.State = TaskStateCancelled
+	task.Error = "task cancelled"
+	task.ErrorCode = CodeCancelled
+	task.FinishedAt = &finished
+	m.notifyLocked(task)
+	m.mu.Unlock()
+
+	if wasScheduled {
+		m.queue.Remove(task)
+	}
+
+	logger.GetLogger().Infof("Task %s cancelled", taskID)
+	return m.snapshot(task), nil
+}
+
+// Watch returns a channel that receives the task as it is now and again on
+// every state change. The channel is closed once the task has finished or
+// stop is called.
+func (m *TaskManager) Watch(taskID string) (<-chan *Task, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[taskID]
+	if !ok {
+		return nil, nil, ErrTaskNotFound
+	}
+
+	// A task changes state at most three times, so the buffer never fills
+	ch := make(chan *Task, 4)
+	copied := *task
+	ch <- &copied
+	if task.State.Finished() {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	m.watchers[taskID] = append(m.watchers[taskID], ch)
+
+	stop := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		watchers := m.watchers[taskID]
+		for i, w := range watchers {
+			if w == ch {
+				m.watchers[taskID] = append(watchers[:i], watchers[i+1:]...)
+				close(ch)
+				break
+			}
+		}
+		if len(m.watchers[taskID]) == 0 {
+			delete(m.watchers, taskID)
+		}
+	}
+	return ch, stop, nil
+}
+
+// Subscribe returns a channel that receives every task on each state change,
+// starting with the tasks submitted after the call. A subscriber that falls
+// behind misses events rather than holding up dispatch. The channel is
+// closed when stop is called.
+func (m *TaskManager) Subscribe() (<-chan *Task, func()) {
+	ch := make(chan *Task, 64)
+
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, ch)
+	m.mu.Unlock()
+
+	stop := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		for i, s := range m.subscribers {
+			if s == ch {
+				m.subscribers = append(m.subscribers[:i], m.subscribers[i+1:]...)
+				close(ch)
+				break
+			}
+		}
+	}
+	return ch, stop
+}
+
+// notifyLocked sends the task to its watchers and subscribers, closing the
+// watchers once it has finished. The caller must hold m.mu.
+func (m *TaskManager) notifyLocked(task *Task) {
+	for _, ch := range m.subscribers {
+		copied := *task
+		select {
+		case ch <- &copied:
+		default:
+		}
+	}
+	for _, ch := range m.watchers[task.ID] {
+		copied := *task
+		select {
+		case ch <- &copied:
+		default:
+		}
+		if task.State.Finished() {
+			close(ch)
+		}
+	}
+	if task.State.Finished() {
+		delete(m.watchers, task.ID)
+
+		m.finished = append(m.finished, task)
+		if len(m.finished) > m.pool.Config().GetMaxFinishedTasks() {
+			select {
+			case m.evict <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// evictFinished forgets finished tasks once they are past tasks.retention,
+// or the oldest ones when more than tasks.max_finished have finished, until
+// Close is called.
+func (m *TaskManager) evictFinished() {
+	ticker := time.NewTicker(evictInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.evict:
+		case <-m.done:
+			return
+		}
+		m.evictNow(time.Now())
+	}
+}
+
+// evictNow evicts the finished tasks that are due for it as of now, along
+// with their idempotency keys.
+func (m *TaskManager) evictNow(now time.Time) {
+	// Reloads may change both limits
+	cfg := m.pool.Config()
+	retention, max := cfg.GetTaskRetention(), cfg.GetMaxFinishedTasks()
+
+	m.mu.Lock()
+	n := 0
+	for n < len(m.finished) {
+		task := m.finished[n]
+		if len(m.finished)-n <= max && now.Sub(*task.FinishedAt) < retention {
+			break
+		}
+		delete(m.tasks, task.ID)
+		n++
+	}
+	evicted := make([]*Task, n)
+	copy(evicted, m.finished[:n])
+	// Clear the evicted entries so their payloads and results can be freed
+	for i := range m.finished[:n] {
+		m.finished[i] = nil
+	}
+	m.finished = m.finished[n:]
+	m.mu.Unlock()
+
+	if n == 0 {
+		return
+	}
+
+	m.idempotency.Lock()
+	for _, task := range evicted {
+		if task.idempotencyKey != "" && m.keys[task.idempotencyKey] == task.ID {
+			delete(m.keys, task.idempotencyKey)
+		}
+	}
+	m.idempotency.Unlock()
+
+	logger.GetLogger().Debugf("Evicted %d finished tasks", n)
+}
+
+// Close stops the delay queue and waits for dispatched tasks to finish.
+func (m *TaskManager) Close() {
+	m.queue.Close()
+	close(m.done)
+	m.wg.Wait()
+}
+
+// markRunning moves a task to the running state unless it was cancelled
+// meanwhile, and reports whether it should still be dispatched.
+func (m *TaskManager) markRunning(task *Task) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if task.State == TaskStateCancelled {
+		return false
+	}
+	if task.State == TaskStateRunning {
+		return true
+	}
+
+	started := time.Now()
+	task.State = TaskStateRunning
+	task.StartedAt = &started
+	m.notifyLocked(task)
+	return true
+}
+
+// execute dispatches a task and records its outcome. The call to the worker
+// is aborted when parent ends, which cancels the task.
+func (m *TaskManager) execute(parent context.Context, task *Task) error {
+	if !m.markRunning(task) {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	m.mu.Lock()
+	cancelled := task.State == TaskStateCancelled
+	task.cancel = cancel
+	m.mu.Unlock()
+	if cancelled {
+		return nil
+	}
+
+	// Deterministic task types reuse the result of an identical task
+	var key string
+	var resp *pb.TaskResponse
+	var err error
+	cached := false
+	if m.types.Deterministic(task.TaskType) {
+		key = resultKey(task)
+		resp, cached = m.cache.Get(key)
+	}
+	if cached {
+		logger.GetLogger().Infof("Task %s answered from the result cache", task.ID)
+	} else {
+		resp, err = m.pool.ProcessTask(ctx, task)
+	}
+
+	// A result that breaks its schema fails the task
+	var resultErr error
+	if err == nil && resp.Success && !cached {
+		resultErr = m.types.ValidateResult(task.TaskType, resp.Result, resp.ContentType)
+		if resultErr == nil && key != "" {
+			m.cache.Put(key, resp)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task.cancel = nil
+	if task.State == TaskStateCancelled {
+		// Cancel already recorded the outcome
+		return nil
+	}
+
+	finished := time.Now()
+	task.FinishedAt = &finished
+	defer m.notifyLocked(task)
+
+	// The submitter stopped waiting for the task
+	if err != nil && parent.Err() != nil {
+		logger.GetLogger().Warnf("Task %s cancelled by its submitter: %v", task.ID, parent.Err())
+		task.State = TaskStateCancelled
+		task.Error = fmt.Sprintf("submission cancelled: %v", parent.Err())
+		task.ErrorCode = CodeCancelled
+		return err
+	}
+
+	// Running out of time is an outcome of the task rather than an error
+	if err != nil && errorCode(err) == CodeDeadlineExceeded {
+		logger.GetLogger().Warnf("Task %s timed out after %s", task.ID, task.Timeout)
+		task.State = TaskStateTimedOut
+		task.Error = fmt.Sprintf("task timed out after %s", task.Timeout)
+		task.ErrorCode = CodeDeadlineExceeded
+		return nil
+	}
+	if err != nil {
+		logger.GetLogger().Errorf("Failed to process task %s: %v", task.ID, err)
+		task.State = TaskStateFailed
+		task.Error = err.Error()
+		task.ErrorCode = errorCode(err)
+		return err
+	}
+
+	task.Success = resp.Success
+	task.Result = resp.Result
+	task.Cached = cached
+	task.ResultContentType = resp.ContentType
+	task.ResultMetadata = resp.Metadata
+	task.Error = resp.Error
+	if !resp.Success {
+		task.ErrorCode = workerErrorCode(resp.ErrorCode)
+	}
+	if resultErr != nil {
+		logger.GetLogger().Errorf("Task %s returned an invalid result: %v", task.ID, resultErr)
+		task.Success = false
+		task.Error = resultErr.Error()
+		task.ErrorCode = CodeInvalidResult
+	}
+	if task.Success {
+		task.State = TaskStateCompleted
+	} else {
+		task.State = TaskStateFailed
+	}
+
+	return nil
+}
+
+func (m *TaskManager) snapshot(task *Task) *Task {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	copied := *task
+	return &copied
+}
@@ -0,0 +1,165 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetLevel("error")
+	os.Exit(m.Run())
+}
+
+// newTestTaskManager returns a task manager whose single worker is never
+// reached, for tests that only hold tasks back and cancel them.
+func newTestTaskManager(t *testing.T, configure func(cfg *config.Config)) *TaskManager {
+	t.Helper()
+
+	cfg := config.Defaults()
+	cfg.Blobs.Dir = t.TempDir()
+	cfg.Workers = []config.WorkerConfig{{ID: "worker-1", URL: "localhost:1"}}
+	if configure != nil {
+		configure(cfg)
+	}
+
+	pool, err := NewWorkerPool(cfg)
+	if err != nil {
+		t.Fatalf("NewWorkerPool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	blobs, err := NewFileBlobStore(cfg.Blobs.Dir)
+	if err != nil {
+		t.Fatalf("NewFileBlobStore: %v", err)
+	}
+
+	m := NewTaskManager(pool, blobs)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// submitCancelled submits a delayed task and cancels it, finishing it
+// without a worker.
+func submitCancelled(t *testing.T, m *TaskManager, key string) *Task {
+	t.Helper()
+
+	task, err := m.SubmitRequest(context.Background(), TaskRequest{TaskType: "compute", Payload: []byte(`"2+2"`), Delay: "1h", IdempotencyKey: key}, false)
+	if err != nil {
+		t.Fatalf("SubmitRequest: %v", err)
+	}
+	if _, err := m.Cancel(task.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	return task
+}
+
+func TestEvictFinishedTasksOverMaxCount(t *testing.T) {
+	m := newTestTaskManager(t, func(cfg *config.Config) {
+		cfg.Tasks.MaxFinished = 2
+	})
+
+	var tasks []*Task
+	for _, key := range []string{"a", "b", "c"} {
+		tasks = append(tasks, submitCancelled(t, m, key))
+	}
+	m.evictNow(time.Now())
+
+	if _, err := m.Get(tasks[0].ID); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("oldest task: Get error = %v, want ErrTaskNotFound", err)
+	}
+	for _, task := range tasks[1:] {
+		if _, err := m.Get(task.ID); err != nil {
+			t.Errorf("Get(%s): %v", task.ID, err)
+		}
+	}
+
+	// The evicted task's idempotency key submits a new task
+	again, err := m.SubmitRequest(context.Background(), TaskRequest{TaskType: "compute", Payload: []byte(`"2+2"`), Delay: "1h", IdempotencyKey: "a"}, false)
+	if err != nil {
+		t.Fatalf("SubmitRequest: %v", err)
+	}
+	if again.ID == tasks[0].ID {
+		t.Error("idempotency key of an evicted task still replays it")
+	}
+}
+
+func TestEvictFinishedTasksPastRetention(t *testing.T) {
+	m := newTestTaskManager(t, func(cfg *config.Config) {
+		cfg.Tasks.Retention = "1m"
+	})
+
+	task := submitCancelled(t, m, "")
+	scheduled, err := m.SubmitRequest(context.Background(), TaskRequest{TaskType: "compute", Payload: []byte(`"2+2"`), Delay: "1h"}, false)
+	if err != nil {
+		t.Fatalf("SubmitRequest: %v", err)
+	}
+
+	m.evictNow(time.Now())
+	if _, err := m.Get(task.ID); err != nil {
+		t.Fatalf("task evicted before its retention: %v", err)
+	}
+
+	m.evictNow(time.Now().Add(2 * time.Minute))
+	if _, err := m.Get(task.ID); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("Get error = %v, want ErrTaskNotFound", err)
+	}
+	// Unfinished tasks are never evicted
+	if _, err := m.Get(scheduled.ID); err != nil {
+		t.Errorf("scheduled task evicted: %v", err)
+	}
+}
+
+// newCachingTaskManager returns a task manager whose compute tasks have a
+// result cached for the payload "2+2", and whose only worker is cordoned.
+func newCachingTaskManager(t *testing.T) (*TaskManager, TaskRequest) {
+	t.Helper()
+
+	types := []config.TaskTypeConfig{{Name: "compute", Deterministic: true}}
+	m := newTestTaskManager(t, func(cfg *config.Config) {
+		cfg.TaskTypes = types
+	})
+	req := TaskRequest{TaskType: "compute", Payload: []byte(`"2+2"`)}
+	task, err := m.newTask(req)
+	if err != nil {
+		t.Fatalf("newTask: %v", err)
+	}
+	m.cache.Put(resultKey(task), &pb.TaskResponse{Success: true, Result: []byte("4"), ContentType: ContentTypeText})
+	if err := m.pool.Cordon("worker-1", true); err != nil {
+		t.Fatalf("Cordon: %v", err)
+	}
+	return m, req
+}
+
+func TestCachedResultWithoutPlaceableWorker(t *testing.T) {
+	m, req := newCachingTaskManager(t)
+
+	task, err := m.SubmitRequest(context.Background(), req, true)
+	if err != nil {
+		t.Fatalf("SubmitRequest: %v", err)
+	}
+	if task.State != TaskStateCompleted || !task.Cached || string(task.Result) != "4" {
+		t.Errorf("got state %s, cached %t, result %q; want a cached completed result", task.State, task.Cached, task.Result)
+	}
+
+	req.Payload = []byte(`"3+3"`)
+	if _, err := m.SubmitRequest(context.Background(), req, true); err == nil {
+		t.Error("uncached task was submitted with every worker cordoned")
+	}
+}
+
+func TestUpdateTaskTypesClearsResultCache(t *testing.T) {
+	m, req := newCachingTaskManager(t)
+
+	if err := m.UpdateTaskTypes([]config.TaskTypeConfig{{Name: "compute", Deterministic: true}}); err != nil {
+		t.Fatalf("UpdateTaskTypes: %v", err)
+	}
+	if _, err := m.SubmitRequest(context.Background(), req, true); err == nil {
+		t.Error("task answered from a result cached before the reload")
+	}
+}
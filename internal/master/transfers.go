@@ -0,0 +1,78 @@
+package master
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/transfer"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+)
+
+// uploadPayload streams a payload to the worker ahead of its ProcessTask
+// call. The first chunk announces the size and checksum the worker verifies.
+func (w *WorkerClient) uploadPayload(ctx context.Context, taskID string, payload []byte, chunkSize int) error {
+	stream, err := w.client.UploadPayload(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to upload payload: %w", err)
+	}
+
+	for i, chunk := range transfer.Split(payload, chunkSize) {
+		msg := &pb.PayloadChunk{Data: chunk}
+		if i == 0 {
+			msg.TaskId = taskID
+			msg.Size = int64(len(payload))
+			msg.Sha256 = transfer.Checksum(payload)
+		}
+		if err := stream.Send(msg); err != nil {
+			// The reason the worker stopped is in CloseAndRecv
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("failed to upload payload: %w", err)
+		}
+	}
+
+	if _, err := stream.CloseAndRecv(); err != nil {
+		return fmt.Errorf("failed to upload payload: %w", err)
+	}
+
+	logger.GetLogger().Infof("Uploaded %d byte payload of task %s to worker %s", len(payload), taskID, w.id)
+	return nil
+}
+
+// downloadResult streams a result the worker held back because it was too
+// large for its response, checking it against the announced size and
+// checksum.
+func (w *WorkerClient) downloadResult(ctx context.Context, taskID string, size int64, checksum string) ([]byte, error) {
+	assembler, err := transfer.NewAssembler(size, checksum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download result: %w", err)
+	}
+
+	stream, err := w.client.DownloadResult(ctx, &pb.DownloadResultRequest{TaskId: taskID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download result: %w", err)
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to download result: %w", err)
+		}
+		if err := assembler.Write(chunk.Data); err != nil {
+			return nil, fmt.Errorf("failed to download result: %w", err)
+		}
+	}
+
+	result, err := assembler.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download result: %w", err)
+	}
+	return result, nil
+}
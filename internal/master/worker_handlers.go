@@ -0,0 +1,109 @@
+package master
+
+import (
+	"net/http"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkerRequest struct {
+	ID  string `json:"id" binding:"required"`
+	URL string `json:"url" binding:"required"`
+	// TaskTypes and Labels pin the worker's capabilities like the config
+	// does; when omitted, the ones the worker advertises are used
+	TaskTypes []string          `json:"task_types,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type WorkerResponse struct {
+	ID        string            `json:"id"`
+	URL       string            `json:"url"`
+	Cordoned  bool              `json:"cordoned"`
+	TaskTypes []string          `json:"task_types,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+func workerResponse(worker *WorkerClient) WorkerResponse {
+	return WorkerResponse{
+		ID:        worker.id,
+		URL:       worker.addr,
+		Cordoned:  worker.Cordoned(),
+		TaskTypes: worker.TaskTypes(),
+		Labels:    worker.Labels(),
+	}
+}
+
+func setupWorkerRoutes(r *gin.RouterGroup, workerPool *WorkerPool) {
+	// List workers endpoint
+	r.GET("/workers", func(c *gin.Context) {
+		workers := workerPool.Workers()
+		resp := make([]WorkerResponse, len(workers))
+		for i, worker := range workers {
+			resp[i] = workerResponse(worker)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"workers": resp})
+	})
+
+	// Add worker endpoint; workers register themselves through it too
+	r.POST("/workers", func(c *gin.Context) {
+		var req WorkerRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, CodeInvalidArgument, err)
+			return
+		}
+
+		created, err := workerPool.AddWorker(config.WorkerConfig{
+			ID:        req.ID,
+			URL:       req.URL,
+			TaskTypes: req.TaskTypes,
+			Labels:    req.Labels,
+		})
+		if err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+
+		worker, err := workerPool.worker(req.ID)
+		if err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		c.JSON(status, workerResponse(worker))
+	})
+
+	// Remove worker endpoint
+	r.DELETE("/workers/:id", func(c *gin.Context) {
+		if err := workerPool.RemoveWorker(c.Param("id")); err != nil {
+			respondError(c, errorCode(err), err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	})
+
+	// Cordon and uncordon endpoints
+	for _, action := range []string{"cordon", "uncordon"} {
+		cordon := action == "cordon"
+		r.POST("/workers/:id/"+action, func(c *gin.Context) {
+			if err := workerPool.Cordon(c.Param("id"), cordon); err != nil {
+				respondError(c, errorCode(err), err)
+				return
+			}
+
+			worker, err := workerPool.worker(c.Param("id"))
+			if err != nil {
+				respondError(c, errorCode(err), err)
+				return
+			}
+			c.JSON(http.StatusOK, workerResponse(worker))
+		})
+	}
+}
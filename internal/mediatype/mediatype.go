@@ -0,0 +1,24 @@
+// Package mediatype holds the content types payloads and results are
+// carried with, shared by the master and its workers.
+package mediatype
+
+import (
+	"mime"
+	"strings"
+)
+
+const (
+	Text   = "text/plain; charset=utf-8"
+	Binary = "application/octet-stream"
+	JSON   = "application/json"
+)
+
+// IsJSON reports whether the content type is application/json or a +json
+// type such as application/problem+json.
+func IsJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == JSON || strings.HasSuffix(mediaType, "+json")
+}
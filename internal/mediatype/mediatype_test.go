@@ -0,0 +1,26 @@
+package mediatype
+
+import "testing"
+
+func TestIsJSON(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"application/problem+json", true},
+		{"APPLICATION/JSON", true},
+		{"text/plain", false},
+		{"application/octet-stream", false},
+		{"application/jsonl", false},
+		{"", false},
+		{"not a media type;;", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsJSON(tt.contentType); got != tt.want {
+			t.Errorf("IsJSON(%q) = %v, want %v", tt.contentType, got, tt.want)
+		}
+	}
+}
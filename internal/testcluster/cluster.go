@@ -0,0 +1,319 @@
+// Package testcluster runs a master and its workers in-process for tests.
+// Workers are real WorkerServers served over in-memory gRPC connections, and
+// the master is the real WorkerPool, TaskManager and gin router, so tests
+// exercise the same code paths as a deployment without ports or sleeps.
+//
+//	c := testcluster.New(t, testcluster.Options{Workers: 2})
+//	task, err := c.Client().SubmitAndWait(ctx, client.TaskRequest{TaskType: "compute", Payload: "2+2"})
+//	c.Worker("worker-1").Kill()
+package testcluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/master"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pkg/client"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type Options struct {
+	// Workers is how many workers to start, named worker-1, worker-2 and so
+	// on. Defaults to 2.
+	Workers int
+	// ConfigureWorker adjusts each worker's config before it starts.
+	// Handlers take no time unless configured otherwise.
+	ConfigureWorker func(cfg *config.WorkerNodeConfig)
+	// ConfigureMaster adjusts the master's config before it connects to the
+	// workers.
+	ConfigureMaster func(cfg *config.Config)
+	// Verbose keeps the master and worker logs; by default only errors are
+	// logged.
+	Verbose bool
+}
+
+// Cluster is a running master with its workers.
+type Cluster struct {
+	Config    *config.Config
+	Pool      *master.WorkerPool
+	Tasks     *master.TaskManager
+	Scheduler *master.Scheduler
+	Router    *gin.Engine
+	// Server serves Router over HTTP for clients that need a URL
+	Server *httptest.Server
+
+	t         testing.TB
+	opts      Options
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	workers map[string]*Worker
+	order   []*Worker
+
+	grpcLis    *bufconn.Listener
+	grpcServer *grpc.Server
+	grpcConn   *grpc.ClientConn
+}
+
+// New starts a cluster and stops it when the test finishes.
+func New(t testing.TB, opts Options) *Cluster {
+	t.Helper()
+
+	if opts.Workers == 0 {
+		opts.Workers = 2
+	}
+
+	// The logger and gin settings are global; restore them once the cluster
+	// has stopped, which runs first as cleanups run last-in first-out
+	level, writer, mode := logger.GetLogger().GetLevel(), gin.DefaultWriter, gin.Mode()
+	t.Cleanup(func() {
+		logger.GetLogger().SetLevel(level)
+		gin.DefaultWriter = writer
+		gin.SetMode(mode)
+	})
+	if !opts.Verbose {
+		logger.SetLevel("error")
+		gin.DefaultWriter = io.Discard
+	}
+	gin.SetMode(gin.TestMode)
+
+	c := &Cluster{
+		t:       t,
+		opts:    opts,
+		workers: make(map[string]*Worker),
+	}
+	t.Cleanup(c.Close)
+
+	// The server's address is known up front so workers can fetch blobs
+	c.Server = httptest.NewUnstartedServer(nil)
+
+	cfg := config.Defaults()
+	cfg.Server.GRPCPort = ""
+	cfg.Blobs.Dir = t.TempDir()
+	for i := 1; i <= opts.Workers; i++ {
+		w := c.StartWorker(fmt.Sprintf("worker-%d", i))
+		cfg.Workers = append(cfg.Workers, config.WorkerConfig{ID: w.ID, URL: w.Addr})
+	}
+	if opts.ConfigureMaster != nil {
+		opts.ConfigureMaster(cfg)
+	}
+	c.Config = cfg
+
+	pool, err := master.NewWorkerPool(cfg, c.DialOptions()...)
+	if err != nil {
+		t.Fatalf("testcluster: failed to create worker pool: %v", err)
+	}
+	c.Pool = pool
+	blobs, err := master.NewFileBlobStore(cfg.Blobs.Dir)
+	if err != nil {
+		t.Fatalf("testcluster: failed to create blob store: %v", err)
+	}
+	c.Tasks = master.NewTaskManager(pool, blobs)
+
+	c.Scheduler, err = master.NewScheduler(c.Tasks, cfg)
+	if err != nil {
+		t.Fatalf("testcluster: failed to create scheduler: %v", err)
+	}
+	c.Scheduler.Start()
+
+	c.Router = master.SetupRoutes(pool, c.Tasks, c.Scheduler, cfg)
+	c.Server.Config.Handler = c.Router
+	c.Server.Start()
+
+	c.grpcLis = bufconn.Listen(bufSize)
+	c.grpcServer = grpc.NewServer(master.ServerOptions(cfg)...)
+	pb.RegisterMasterServiceServer(c.grpcServer, master.NewMasterServer(pool, c.Tasks))
+	go c.grpcServer.Serve(c.grpcLis)
+
+	return c
+}
+
+// StartWorker starts a worker that the cluster can dial. Workers started
+// after New are not in the pool until added, e.g. with POST /workers and
+// the worker's Addr.
+func (c *Cluster) StartWorker(id string) *Worker {
+	c.t.Helper()
+
+	cfg := config.DefaultWorkerNodeConfig()
+	cfg.ID = id
+	cfg.BlobCache.Source = c.Server.Listener.Addr().String()
+	cfg.BlobCache.Dir = c.t.TempDir()
+	cfg.Handlers = make(map[string]config.HandlerConfig)
+	for _, taskType := range config.DefaultTaskTypes {
+		cfg.Handlers[taskType] = config.HandlerConfig{Delay: "0s"}
+	}
+	if c.opts.ConfigureWorker != nil {
+		c.opts.ConfigureWorker(cfg)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := newWorker(cfg)
+	if _, ok := c.workers[w.Addr]; ok {
+		c.t.Fatalf("testcluster: worker %s already exists", id)
+	}
+	w.start()
+	c.workers[w.Addr] = w
+	c.order = append(c.order, w)
+	return w
+}
+
+// Worker returns the worker with the given ID, failing the test when there
+// is none.
+func (c *Cluster) Worker(id string) *Worker {
+	c.t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, w := range c.order {
+		if w.ID == id {
+			return w
+		}
+	}
+	c.t.Fatalf("testcluster: no worker %s", id)
+	return nil
+}
+
+// Workers returns every worker started, in order.
+func (c *Cluster) Workers() []*Worker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Worker(nil), c.order...)
+}
+
+// DialOptions returns the options that connect to the cluster's workers by
+// their Addr. Reconnecting after a restart is retried quickly.
+func (c *Cluster) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithContextDialer(c.dial),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff: backoff.Config{
+				BaseDelay:  10 * time.Millisecond,
+				Multiplier: 1.6,
+				MaxDelay:   100 * time.Millisecond,
+			},
+			MinConnectTimeout: time.Second,
+		}),
+	}
+}
+
+func (c *Cluster) dial(ctx context.Context, addr string) (net.Conn, error) {
+	c.mu.Lock()
+	w, ok := c.workers[addr]
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no worker at %s", addr)
+	}
+	return w.dial(ctx)
+}
+
+// URL is the base URL of the master's REST API.
+func (c *Cluster) URL() string {
+	return c.Server.URL
+}
+
+// Client returns an SDK client for the master.
+func (c *Cluster) Client(opts ...client.Option) *client.Client {
+	c.t.Helper()
+
+	cl, err := client.New(c.URL(), opts...)
+	if err != nil {
+		c.t.Fatalf("testcluster: failed to create client: %v", err)
+	}
+	return cl
+}
+
+// MasterClient returns a client for the master's gRPC API.
+func (c *Cluster) MasterClient() pb.MasterServiceClient {
+	c.t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.grpcConn == nil {
+		conn, err := grpc.Dial("bufconn-master",
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return c.grpcLis.DialContext(ctx)
+			}),
+		)
+		if err != nil {
+			c.t.Fatalf("testcluster: failed to dial master: %v", err)
+		}
+		c.grpcConn = conn
+	}
+	return pb.NewMasterServiceClient(c.grpcConn)
+}
+
+// Do sends a request to the REST API without going through the network. A
+// non-nil body is encoded as JSON.
+func (c *Cluster) Do(method, path string, body interface{}) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("testcluster: failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rec := httptest.NewRecorder()
+	c.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+// Close stops the master and every worker. It is called when the test
+// finishes.
+func (c *Cluster) Close() {
+	c.closeOnce.Do(c.close)
+}
+
+func (c *Cluster) close() {
+	if c.Server != nil {
+		c.Server.Close()
+	}
+	if c.grpcConn != nil {
+		c.grpcConn.Close()
+	}
+	if c.grpcServer != nil {
+		c.grpcServer.Stop()
+	}
+	if c.Scheduler != nil {
+		c.Scheduler.Stop()
+	}
+	if c.Tasks != nil {
+		c.Tasks.Close()
+	}
+	if c.Pool != nil {
+		c.Pool.Close()
+	}
+	for _, w := range c.Workers() {
+		w.Kill()
+	}
+}
@@ -0,0 +1,459 @@
+package testcluster_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/testcluster"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pkg/client"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// newClient returns a client that polls often enough for tests.
+func newClient(c *testcluster.Cluster) *client.Client {
+	return c.Client(client.WithPollInterval(10 * time.Millisecond))
+}
+
+func submitAndWait(t *testing.T, cl *client.Client, req client.TaskRequest) *client.Task {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	task, err := cl.SubmitAndWait(ctx, req)
+	if err != nil {
+		t.Fatalf("SubmitAndWait: %v", err)
+	}
+	return task
+}
+
+// calls returns how many tasks the cluster's workers were sent.
+func calls(c *testcluster.Cluster) int {
+	n := 0
+	for _, w := range c.Workers() {
+		n += w.Calls()
+	}
+	return n
+}
+
+// labelWorkers puts worker-1 in zone a and the others in zone b.
+func labelWorkers(cfg *config.WorkerNodeConfig) {
+	zone := "b"
+	if cfg.ID == "worker-1" {
+		zone = "a"
+	}
+	cfg.Labels = map[string]string{"zone": zone}
+}
+
+func TestSmoke(t *testing.T) {
+	const chunkSize = 1024
+	c := testcluster.New(t, testcluster.Options{
+		ConfigureWorker: func(cfg *config.WorkerNodeConfig) {
+			cfg.ChunkSize = chunkSize
+			cfg.Handlers["process"] = config.HandlerConfig{Delay: "1m"}
+		},
+		ConfigureMaster: func(cfg *config.Config) {
+			cfg.GRPC.ChunkSize = chunkSize
+		},
+	})
+	cl := newClient(c)
+
+	ctx := context.Background()
+	blob, err := cl.PutBlob(ctx, []byte("payload kept as a blob"))
+	if err != nil {
+		t.Fatalf("PutBlob: %v", err)
+	}
+	large := strings.Repeat("x", 10*chunkSize)
+
+	tests := []struct {
+		name  string
+		req   client.TaskRequest
+		state client.TaskState
+		check func(t *testing.T, task *client.Task)
+	}{
+		{
+			name:  "text",
+			req:   client.TaskRequest{TaskType: "compute", Payload: "2+2"},
+			state: client.TaskStateCompleted,
+			check: func(t *testing.T, task *client.Task) {
+				if task.Result != "Computed result for: 2+2" {
+					t.Errorf("result = %q", task.Result)
+				}
+			},
+		},
+		{
+			name:  "JSON",
+			req:   client.TaskRequest{TaskType: "compute", Value: map[string]int{"a": 1}},
+			state: client.TaskStateCompleted,
+			check: func(t *testing.T, task *client.Task) {
+				var result struct {
+					Computed map[string]int `json:"computed"`
+				}
+				if err := task.DecodeResult(&result); err != nil || result.Computed["a"] != 1 {
+					t.Errorf("result = %s (%v)", task.ResultJSON, err)
+				}
+			},
+		},
+		{
+			name:  "binary",
+			req:   client.TaskRequest{TaskType: "compute", Data: []byte{0xff, 0xfe, 0x00}},
+			state: client.TaskStateCompleted,
+			check: func(t *testing.T, task *client.Task) {
+				if task.Result != "Computed result for: 3 bytes of application/octet-stream" {
+					t.Errorf("result = %q", task.Result)
+				}
+			},
+		},
+		{
+			name:  "chunked",
+			req:   client.TaskRequest{TaskType: "compute", Payload: large},
+			state: client.TaskStateCompleted,
+			check: func(t *testing.T, task *client.Task) {
+				if task.Result != "Computed result for: "+large {
+					t.Errorf("result has %d bytes, want %d", len(task.Result), len("Computed result for: "+large))
+				}
+			},
+		},
+		{
+			name:  "blob",
+			req:   client.TaskRequest{TaskType: "compute", Payload: blob.Ref},
+			state: client.TaskStateCompleted,
+			check: func(t *testing.T, task *client.Task) {
+				if task.Result != "Computed result for: payload kept as a blob" {
+					t.Errorf("result = %q", task.Result)
+				}
+			},
+		},
+		{
+			name:  "timeout",
+			req:   client.TaskRequest{TaskType: "process", Payload: "slow", Timeout: 50 * time.Millisecond},
+			state: client.TaskStateTimedOut,
+			check: func(t *testing.T, task *client.Task) {
+				if task.ErrorCode != "deadline_exceeded" {
+					t.Errorf("error code = %q", task.ErrorCode)
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			task := submitAndWait(t, cl, test.req)
+			if task.State != test.state {
+				t.Fatalf("state = %s (%s), want %s", task.State, task.Error, test.state)
+			}
+			if test.check != nil {
+				test.check(t, task)
+			}
+		})
+	}
+}
+
+func TestSelectorsAndRouting(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 3, ConfigureWorker: labelWorkers})
+	cl := newClient(c)
+
+	for i := 0; i < 4; i++ {
+		task := submitAndWait(t, cl, client.TaskRequest{TaskType: "compute", Payload: "2+2", Selector: "zone=a"})
+		if worker := task.ResultMetadata["worker_id"]; worker != "worker-1" {
+			t.Errorf("selector zone=a ran on %s, want worker-1", worker)
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		task := submitAndWait(t, cl, client.TaskRequest{
+			TaskType:    "compute",
+			Payload:     "2+2",
+			Preferences: []client.Preference{{Selector: "zone=b", Weight: 10}},
+		})
+		if worker := task.ResultMetadata["worker_id"]; worker == "worker-1" {
+			t.Errorf("preference for zone=b ran on %s", worker)
+		}
+	}
+
+	routed := ""
+	for i := 0; i < 6; i++ {
+		task := submitAndWait(t, cl, client.TaskRequest{TaskType: "compute", Payload: "2+2", RoutingKey: "user-1"})
+		worker := task.ResultMetadata["worker_id"]
+		if routed == "" {
+			routed = worker
+		} else if worker != routed {
+			t.Errorf("routing key user-1 ran on %s and %s", routed, worker)
+		}
+	}
+
+	_, err := cl.Submit(context.Background(), client.TaskRequest{TaskType: "compute", Payload: "2+2", Selector: "zone=c"})
+	if !errors.Is(err, client.ErrUnplaceable) {
+		t.Errorf("selector matching no worker: got %v, want %v", err, client.ErrUnplaceable)
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{})
+	cl := newClient(c)
+	ctx := context.Background()
+
+	req := client.TaskRequest{TaskType: "compute", Payload: "2+2", IdempotencyKey: "order-1"}
+	first := submitAndWait(t, cl, req)
+	again, err := cl.Submit(ctx, req)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if again.ID != first.ID {
+		t.Errorf("retried submission created task %s, want %s", again.ID, first.ID)
+	}
+	if n := calls(c); n != 1 {
+		t.Errorf("workers were sent %d tasks, want 1", n)
+	}
+
+	req.Payload = "3+3"
+	if _, err := cl.Submit(ctx, req); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("key reused for another task: got %v, want %v", err, client.ErrConflict)
+	}
+}
+
+func TestCancelRunningTask(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+	c.Worker("worker-1").SetLatency(time.Minute)
+	cl := newClient(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	task, err := cl.Submit(ctx, client.TaskRequest{TaskType: "compute", Payload: "2+2"})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	watch, err := c.MasterClient().WatchTask(ctx, &pb.WatchTaskRequest{TaskId: task.ID})
+	if err != nil {
+		t.Fatalf("WatchTask: %v", err)
+	}
+
+	// Wait for the task to reach the worker before cancelling it
+	for {
+		update, err := watch.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if update.State == pb.TaskState_TASK_STATE_RUNNING {
+			break
+		}
+	}
+	if _, err := cl.Cancel(ctx, task.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	update, err := watch.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if update.State != pb.TaskState_TASK_STATE_CANCELLED {
+		t.Errorf("watched state = %s, want cancelled", update.State)
+	}
+	if _, err := cl.Cancel(ctx, task.ID); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("cancelling a finished task: got %v, want %v", err, client.ErrConflict)
+	}
+}
+
+func TestChunkedTransfersAboveMessageLimit(t *testing.T) {
+	const maxMessageSize = 64 * 1024
+	c := testcluster.New(t, testcluster.Options{
+		Workers: 1,
+		ConfigureWorker: func(cfg *config.WorkerNodeConfig) {
+			cfg.MaxMessageSize = maxMessageSize
+			cfg.ChunkSize = maxMessageSize / 4
+		},
+		ConfigureMaster: func(cfg *config.Config) {
+			cfg.GRPC.MaxMessageSize = maxMessageSize
+			cfg.GRPC.ChunkSize = maxMessageSize / 4
+		},
+	})
+	cl := newClient(c)
+
+	// Binary data that is not valid UTF-8 comes back summarized, so the
+	// payload is text to get all of it back
+	payload := strings.Repeat("0123456789abcdef", 4*maxMessageSize/16)
+	task := submitAndWait(t, cl, client.TaskRequest{TaskType: "compute", Payload: payload})
+	if task.State != client.TaskStateCompleted {
+		t.Fatalf("state = %s (%s), want completed", task.State, task.Error)
+	}
+	if task.Result != "Computed result for: "+payload {
+		t.Errorf("result has %d bytes, want %d", len(task.Result), len("Computed result for: "+payload))
+	}
+}
+
+func TestBlobPayloads(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{})
+	cl := newClient(c)
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("blob "), 1000)
+	blob, err := cl.PutBlob(ctx, data)
+	if err != nil {
+		t.Fatalf("PutBlob: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		task := submitAndWait(t, cl, client.TaskRequest{TaskType: "compute", Payload: blob.Ref})
+		if task.State != client.TaskStateCompleted || task.PayloadBlob != blob.Digest {
+			t.Fatalf("state = %s (%s), payload blob %q; want completed with %s", task.State, task.Error, task.PayloadBlob, blob.Digest)
+		}
+		if task.Result != "Computed result for: "+string(data) {
+			t.Errorf("result has %d bytes, want %d", len(task.Result), len("Computed result for: "+string(data)))
+		}
+	}
+
+	// A task naming a blob that does not exist is an invalid request
+	missing := "blob://sha256:" + strings.Repeat("0", 64)
+	if _, err := cl.Submit(ctx, client.TaskRequest{TaskType: "compute", Payload: missing}); !errors.Is(err, client.ErrInvalidRequest) {
+		t.Errorf("missing blob: got %v, want %v", err, client.ErrInvalidRequest)
+	}
+}
+
+func TestResultCache(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{
+		ConfigureMaster: func(cfg *config.Config) {
+			cfg.TaskTypes = []config.TaskTypeConfig{{Name: "compute", Deterministic: true}}
+		},
+	})
+	cl := newClient(c)
+	ctx := context.Background()
+
+	first := submitAndWait(t, cl, client.TaskRequest{TaskType: "compute", Payload: "2+2"})
+	second := submitAndWait(t, cl, client.TaskRequest{TaskType: "compute", Payload: "2+2"})
+	if first.Cached || !second.Cached || second.Result != first.Result {
+		t.Errorf("cached = %t then %t, results %q and %q; want the second reused", first.Cached, second.Cached, first.Result, second.Result)
+	}
+	if n := calls(c); n != 1 {
+		t.Errorf("workers were sent %d tasks, want 1", n)
+	}
+
+	// A cached result needs no worker
+	for _, w := range c.Workers() {
+		if _, err := cl.Cordon(ctx, w.ID); err != nil {
+			t.Fatalf("Cordon: %v", err)
+		}
+	}
+	if task := submitAndWait(t, cl, client.TaskRequest{TaskType: "compute", Payload: "2+2"}); !task.Cached {
+		t.Error("cached result not returned with every worker cordoned")
+	}
+	if _, err := cl.Submit(ctx, client.TaskRequest{TaskType: "compute", Payload: "3+3"}); !errors.Is(err, client.ErrUnavailable) {
+		t.Errorf("uncached task with every worker cordoned: got %v, want %v", err, client.ErrUnavailable)
+	}
+}
+
+func TestTaskTypeTimeouts(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{
+		Workers: 1,
+		ConfigureMaster: func(cfg *config.Config) {
+			cfg.TaskTypes = []config.TaskTypeConfig{{Name: "compute", Timeout: "50ms", MaxTimeout: "1s"}}
+		},
+	})
+	c.Worker("worker-1").SetLatency(time.Minute)
+	cl := newClient(c)
+
+	task := submitAndWait(t, cl, client.TaskRequest{TaskType: "compute", Payload: "2+2"})
+	if task.State != client.TaskStateTimedOut || task.Timeout != "50ms" {
+		t.Errorf("state = %s with timeout %s, want timed_out after the type's 50ms", task.State, task.Timeout)
+	}
+
+	_, err := cl.Submit(context.Background(), client.TaskRequest{TaskType: "compute", Payload: "2+2", Timeout: time.Minute})
+	if !errors.Is(err, client.ErrInvalidRequest) {
+		t.Errorf("timeout above the maximum: got %v, want %v", err, client.ErrInvalidRequest)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1, ConfigureWorker: labelWorkers})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+		setup  func()
+	}{
+		{"invalid JSON", http.MethodPost, "/v1/tasks", `{"task_type": `, http.StatusBadRequest, "invalid_argument", nil},
+		{"body against the API schema", http.MethodPost, "/v1/tasks", `{"task_type": 5}`, http.StatusBadRequest, "invalid_argument", nil},
+		{"missing blob", http.MethodPost, "/v1/tasks", `{"task_type": "compute", "payload": "blob://sha256:` + strings.Repeat("0", 64) + `"}`, http.StatusBadRequest, "invalid_argument", nil},
+		{"unknown task", http.MethodGet, "/v1/tasks/missing", "", http.StatusNotFound, "not_found", nil},
+		{"unsupported task type", http.MethodPost, "/v1/tasks", `{"task_type": "render", "payload": "x"}`, http.StatusUnprocessableEntity, "unknown_task_type", nil},
+		{"no matching worker", http.MethodPost, "/v1/tasks", `{"task_type": "compute", "payload": "x", "selector": "zone=c"}`, http.StatusUnprocessableEntity, "no_matching_worker", nil},
+		{"timed out", http.MethodPost, "/v1/tasks", `{"task_type": "compute", "payload": "x", "timeout": "50ms"}`, http.StatusGatewayTimeout, "deadline_exceeded",
+			func() { c.Worker("worker-1").SetLatency(time.Minute) }},
+		{"worker down", http.MethodPost, "/v1/tasks", `{"task_type": "compute", "payload": "x"}`, http.StatusServiceUnavailable, "unavailable",
+			func() { c.Worker("worker-1").Kill() }},
+		{"every worker cordoned", http.MethodPost, "/v1/tasks", `{"task_type": "compute", "payload": "x"}`, http.StatusServiceUnavailable, "unavailable",
+			func() {
+				c.Worker("worker-1").Restart()
+				c.Worker("worker-1").SetLatency(0)
+				if err := c.Pool.Cordon("worker-1", true); err != nil {
+					t.Fatalf("Cordon: %v", err)
+				}
+			}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.setup != nil {
+				test.setup()
+			}
+
+			// Bodies are sent as they are, as some are not valid JSON
+			var body io.Reader
+			if test.body != "" {
+				body = strings.NewReader(test.body)
+			}
+			req := httptest.NewRequest(test.method, test.path, body)
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c.Router.ServeHTTP(rec, req)
+			if rec.Code != test.status {
+				t.Fatalf("%s %s = %d %s, want %d", test.method, test.path, rec.Code, rec.Body, test.status)
+			}
+
+			// Errors carry a code, and tasks that were created an error_code
+			var resp struct {
+				Code      string `json:"code"`
+				ErrorCode string `json:"error_code"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if code := resp.Code + resp.ErrorCode; code != test.code {
+				t.Errorf("code = %q, want %q", code, test.code)
+			}
+		})
+	}
+}
+
+func TestNewRestoresLogging(t *testing.T) {
+	level, writer, mode := logger.GetLogger().GetLevel(), gin.DefaultWriter, gin.Mode()
+
+	t.Run("cluster", func(t *testing.T) {
+		testcluster.New(t, testcluster.Options{Workers: 1})
+		if got := logger.GetLogger().GetLevel(); got != logrus.ErrorLevel {
+			t.Errorf("log level = %s while the cluster runs, want %s", got, logrus.ErrorLevel)
+		}
+	})
+
+	if got := logger.GetLogger().GetLevel(); got != level {
+		t.Errorf("log level = %s after the cluster stopped, want %s", got, level)
+	}
+	if gin.DefaultWriter != writer {
+		t.Error("gin.DefaultWriter not restored after the cluster stopped")
+	}
+	if got := gin.Mode(); got != mode {
+		t.Errorf("gin mode = %s after the cluster stopped, want %s", got, mode)
+	}
+}
@@ -0,0 +1,127 @@
+package testcluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/worker"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1024 * 1024
+
+// Worker is a WorkerServer served over an in-memory listener. It can be
+// killed and restarted at the same address, and made slower.
+type Worker struct {
+	ID string
+	// Addr is the address the master dials to reach the worker
+	Addr   string
+	Config *config.WorkerNodeConfig
+
+	mu      sync.Mutex
+	lis     *bufconn.Listener
+	server  *grpc.Server
+	handler *worker.WorkerServer
+
+	latency atomic.Int64
+	calls   atomic.Int64
+}
+
+func newWorker(cfg *config.WorkerNodeConfig) *Worker {
+	return &Worker{
+		ID:     cfg.ID,
+		Addr:   "bufconn-" + cfg.ID,
+		Config: cfg,
+	}
+}
+
+func (w *Worker) start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.server != nil {
+		return
+	}
+
+	w.lis = bufconn.Listen(bufSize)
+	w.server = grpc.NewServer(append(worker.ServerOptions(w.Config), grpc.UnaryInterceptor(w.intercept))...)
+	w.handler = worker.NewWorkerServer(w.Config)
+	pb.RegisterWorkerServiceServer(w.server, w.handler)
+
+	go w.server.Serve(w.lis)
+}
+
+// Kill stops the worker abruptly: calls in flight fail and new connections
+// are refused until Restart.
+func (w *Worker) Kill() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.server == nil {
+		return
+	}
+	w.server.Stop()
+	w.handler.Close()
+	w.server = nil
+	w.handler = nil
+	w.lis = nil
+}
+
+// Restart starts the worker again at the same address with a fresh
+// WorkerServer, killing it first when it is running.
+func (w *Worker) Restart() {
+	w.Kill()
+	w.start()
+}
+
+func (w *Worker) Running() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.server != nil
+}
+
+// SetLatency delays every call to the worker by d before it is handled.
+func (w *Worker) SetLatency(d time.Duration) {
+	w.latency.Store(int64(d))
+}
+
+// Calls returns how many tasks the worker has been asked to process.
+func (w *Worker) Calls() int {
+	return int(w.calls.Load())
+}
+
+func (w *Worker) dial(ctx context.Context) (net.Conn, error) {
+	w.mu.Lock()
+	lis := w.lis
+	w.mu.Unlock()
+
+	if lis == nil {
+		return nil, fmt.Errorf("worker %s is down", w.ID)
+	}
+	return lis.DialContext(ctx)
+}
+
+func (w *Worker) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if info.FullMethod == pb.WorkerService_ProcessTask_FullMethodName {
+		w.calls.Add(1)
+	}
+
+	if latency := time.Duration(w.latency.Load()); latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+
+	return handler(ctx, req)
+}
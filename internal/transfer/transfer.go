@@ -0,0 +1,80 @@
+// Package transfer splits payloads and results that are too large for a
+// single gRPC message into chunks, and reassembles them, checking their size
+// and SHA-256 checksum.
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const maxPrealloc = 64 * 1024 * 1024
+
+var (
+	ErrSizeMismatch     = errors.New("transfer size mismatch")
+	ErrChecksumMismatch = errors.New("transfer checksum mismatch")
+)
+
+// Checksum returns the hex-encoded SHA-256 of data.
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Split returns data as consecutive chunks of at most size bytes, without
+// copying it.
+func Split(data []byte, size int) [][]byte {
+	chunks := make([][]byte, 0, len(data)/size+1)
+	for len(data) > size {
+		chunks = append(chunks, data[:size])
+		data = data[size:]
+	}
+	return append(chunks, data)
+}
+
+// Assembler collects the chunks of a transfer whose size and checksum were
+// announced up front.
+type Assembler struct {
+	size     int64
+	checksum string
+	buf      bytes.Buffer
+}
+
+// NewAssembler prepares to receive size bytes.
+func NewAssembler(size int64, checksum string) (*Assembler, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("%w: negative size %d", ErrSizeMismatch, size)
+	}
+	if checksum == "" {
+		return nil, fmt.Errorf("a sha256 checksum is required")
+	}
+
+	a := &Assembler{size: size, checksum: checksum}
+	// The announced size is not trusted until the data arrives
+	a.buf.Grow(int(min(size, maxPrealloc)))
+	return a, nil
+}
+
+// Write adds the next chunk.
+func (a *Assembler) Write(chunk []byte) error {
+	if int64(a.buf.Len()+len(chunk)) > a.size {
+		return fmt.Errorf("%w: received more than the %d bytes announced", ErrSizeMismatch, a.size)
+	}
+	a.buf.Write(chunk)
+	return nil
+}
+
+// Bytes returns the assembled data once it is complete and matches its
+// checksum.
+func (a *Assembler) Bytes() ([]byte, error) {
+	if int64(a.buf.Len()) != a.size {
+		return nil, fmt.Errorf("%w: received %d of %d bytes", ErrSizeMismatch, a.buf.Len(), a.size)
+	}
+	if sum := Checksum(a.buf.Bytes()); sum != a.checksum {
+		return nil, fmt.Errorf("%w: got sha256 %s, want %s", ErrChecksumMismatch, sum, a.checksum)
+	}
+	return a.buf.Bytes(), nil
+}
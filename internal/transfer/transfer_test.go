@@ -0,0 +1,85 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func assemble(t *testing.T, size int64, checksum string, chunks ...[]byte) ([]byte, error) {
+	t.Helper()
+
+	a, err := NewAssembler(size, checksum)
+	if err != nil {
+		t.Fatalf("NewAssembler: %v", err)
+	}
+	for _, chunk := range chunks {
+		if err := a.Write(chunk); err != nil {
+			return nil, err
+		}
+	}
+	return a.Bytes()
+}
+
+func TestAssembler(t *testing.T) {
+	data := []byte("hello, world")
+
+	tests := []struct {
+		name     string
+		size     int64
+		checksum string
+		chunks   [][]byte
+		want     error
+	}{
+		{"complete", 12, Checksum(data), Split(data, 5), nil},
+		{"empty", 0, Checksum(nil), nil, nil},
+		{"checksum mismatch", 12, Checksum([]byte("hello, there")), Split(data, 5), ErrChecksumMismatch},
+		{"oversize upload", 11, Checksum(data[:11]), Split(data, 5), ErrSizeMismatch},
+		{"short upload", 20, Checksum(data), Split(data, 5), ErrSizeMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := assemble(t, test.size, test.checksum, test.chunks...)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("got error %v, want %v", err, test.want)
+			}
+			if err == nil && !bytes.Equal(got, data[:test.size]) {
+				t.Errorf("got %q, want %q", got, data[:test.size])
+			}
+		})
+	}
+}
+
+func TestNewAssemblerErrors(t *testing.T) {
+	if _, err := NewAssembler(-1, Checksum(nil)); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("negative size: got %v, want %v", err, ErrSizeMismatch)
+	}
+	if _, err := NewAssembler(1, ""); err == nil {
+		t.Error("missing checksum: got no error")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		data string
+		size int
+		want []string
+	}{
+		{"", 4, []string{""}},
+		{"abc", 4, []string{"abc"}},
+		{"abcd", 4, []string{"abcd"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+	}
+	for _, test := range tests {
+		chunks := Split([]byte(test.data), test.size)
+		if len(chunks) != len(test.want) {
+			t.Errorf("Split(%q, %d) = %d chunks, want %d", test.data, test.size, len(chunks), len(test.want))
+			continue
+		}
+		for i, chunk := range chunks {
+			if string(chunk) != test.want[i] {
+				t.Errorf("Split(%q, %d)[%d] = %q, want %q", test.data, test.size, i, chunk, test.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/transfer"
+)
+
+var blobDigest = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// blobFetchTimeout bounds a download from the master. It is not tied to any
+// one task, since every task needing the blob waits for the same download.
+const blobFetchTimeout = 5 * time.Minute
+
+// blobCache keeps the blobs payloads refer to on disk, named by their hex
+// digest, fetching them from the master the first time they are needed.
+// Blobs beyond the size limit are evicted least recently used first.
+type blobCache struct {
+	dir     string
+	maxSize int64
+
+	mu       sync.Mutex
+	fetching map[string]*blobFetch
+}
+
+// blobFetch is a download in progress that other tasks needing the same blob
+// wait for.
+type blobFetch struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+func newBlobCache(dir string, maxSize int64) *blobCache {
+	return &blobCache{
+		dir:      dir,
+		maxSize:  maxSize,
+		fetching: make(map[string]*blobFetch),
+	}
+}
+
+// get returns a blob's content, fetching it from the master's HTTP API at
+// masterAddr when it is not cached.
+func (c *blobCache) get(ctx context.Context, masterAddr, digest string) ([]byte, error) {
+	if !blobDigest.MatchString(digest) {
+		return nil, fmt.Errorf("invalid blob digest %q", digest)
+	}
+
+	path := filepath.Join(c.dir, strings.TrimPrefix(digest, "sha256:"))
+	if data, err := os.ReadFile(path); err == nil {
+		// The modification time records the last use for eviction
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		return data, nil
+	}
+
+	if masterAddr == "" {
+		return nil, fmt.Errorf("blob %s is not cached and neither blob_cache.source nor master.address is set", digest)
+	}
+	return c.fetch(ctx, masterAddr, digest, path)
+}
+
+// fetch downloads a blob once however many tasks ask for it at the same time.
+// The download outlives a task that gives up on it, so the others still get
+// the blob.
+func (c *blobCache) fetch(ctx context.Context, masterAddr, digest, path string) ([]byte, error) {
+	c.mu.Lock()
+	f, ok := c.fetching[digest]
+	if !ok {
+		f = &blobFetch{done: make(chan struct{})}
+		c.fetching[digest] = f
+		go c.run(f, masterAddr, digest, path)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.data, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *blobCache) run(f *blobFetch, masterAddr, digest, path string) {
+	ctx, cancel := context.WithTimeout(context.Background(), blobFetchTimeout)
+	defer cancel()
+
+	f.data, f.err = c.download(ctx, masterAddr, digest)
+	if f.err == nil {
+		if err := c.store(path, f.data); err != nil {
+			logger.GetLogger().Warnf("Failed to cache blob %s: %v", digest, err)
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.fetching, digest)
+	c.mu.Unlock()
+	close(f.done)
+}
+
+func (c *blobCache) download(ctx context.Context, masterAddr, digest string) ([]byte, error) {
+	url := fmt.Sprintf("http://%s/v1/blobs/%s", masterAddr, digest)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("master answered %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if sum := "sha256:" + transfer.Checksum(data); sum != digest {
+		return nil, fmt.Errorf("%w: got %s", transfer.ErrChecksumMismatch, sum)
+	}
+
+	logger.GetLogger().Infof("Fetched blob %s (%d bytes) from master", digest, len(data))
+	return data, nil
+}
+
+// store writes a blob to the cache and evicts older ones to make room.
+func (c *blobCache) store(path string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".fetch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	c.evict(path)
+	return nil
+}
+
+// evict removes the least recently used blobs until the cache fits its size
+// limit, keeping the blob at keep.
+func (c *blobCache) evict(keep string) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	var infos []os.FileInfo
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !blobDigest.MatchString("sha256:"+entry.Name()) {
+			continue
+		}
+		infos = append(infos, info)
+		total += info.Size()
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+	for _, info := range infos {
+		if total <= c.maxSize {
+			return
+		}
+		path := filepath.Join(c.dir, info.Name())
+		if path == keep {
+			continue
+		}
+		if err := os.Remove(path); err == nil {
+			total -= info.Size()
+			logger.GetLogger().Debugf("Evicted blob sha256:%s from the cache", info.Name())
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/transfer"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+)
+
+func TestProcessTaskFetchesBlobFromSource(t *testing.T) {
+	blob := []byte("blob payload")
+	digest := "sha256:" + transfer.Checksum(blob)
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/blobs/"+digest {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(blob)
+	}))
+	defer master.Close()
+
+	tests := []struct {
+		name   string
+		source string
+		ok     bool
+	}{
+		{"blob source without registering", master.Listener.Addr().String(), true},
+		{"no blob source", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.DefaultWorkerNodeConfig()
+			cfg.BlobCache.Dir = t.TempDir()
+			cfg.BlobCache.Source = test.source
+			cfg.Handlers = map[string]config.HandlerConfig{"compute": {Delay: "0s"}}
+			s := NewWorkerServer(cfg)
+			defer s.Close()
+
+			resp, err := s.ProcessTask(context.Background(), &pb.TaskRequest{
+				TaskId:      "task-1",
+				TaskType:    "compute",
+				PayloadBlob: digest,
+			})
+			if err != nil {
+				t.Fatalf("ProcessTask: %v", err)
+			}
+			if resp.Success != test.ok {
+				t.Errorf("got success %t (%s), want %t", resp.Success, resp.Error, test.ok)
+			}
+		})
+	}
+}
+
+// blockingMaster serves a blob once release is closed.
+func blockingMaster(t *testing.T, blob []byte) (addr string, release chan struct{}) {
+	t.Helper()
+
+	release = make(chan struct{})
+	master := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write(blob)
+	}))
+	t.Cleanup(master.Close)
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+	return master.Listener.Addr().String(), release
+}
+
+func fetching(c *blobCache, digest string) *blobFetch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fetching[digest]
+}
+
+func TestBlobFetchOutlivesFirstCaller(t *testing.T) {
+	blob := []byte("blob payload")
+	digest := "sha256:" + transfer.Checksum(blob)
+	addr, release := blockingMaster(t, blob)
+	cache := newBlobCache(t.TempDir(), 1<<20)
+
+	// The task that started the download gives up on it
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cache.get(ctx, addr, digest)
+		first <- err
+	}()
+	for fetching(cache, digest) == nil {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan []byte, 1)
+	go func() {
+		data, _ := cache.get(context.Background(), addr, digest)
+		second <- data
+	}()
+
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Fatalf("first get = %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	select {
+	case data := <-second:
+		if string(data) != string(blob) {
+			t.Errorf("second get = %q, want %q", data, blob)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second get did not return")
+	}
+}
+
+func TestBlobFetchTakesTaskSlot(t *testing.T) {
+	blob := []byte("blob payload")
+	digest := "sha256:" + transfer.Checksum(blob)
+	addr, release := blockingMaster(t, blob)
+
+	cfg := config.DefaultWorkerNodeConfig()
+	cfg.Concurrency = 1
+	cfg.BlobCache.Dir = t.TempDir()
+	cfg.BlobCache.Source = addr
+	cfg.Handlers = map[string]config.HandlerConfig{"compute": {Delay: "0s"}}
+	s := NewWorkerServer(cfg)
+	defer s.Close()
+
+	fetching := make(chan struct{})
+	go func() {
+		defer close(fetching)
+		s.ProcessTask(context.Background(), &pb.TaskRequest{TaskId: "task-1", TaskType: "compute", PayloadBlob: digest})
+	}()
+	// Wait for the blob task to hold the only slot
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.slots) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("fetching a blob did not take a task slot")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := s.ProcessTask(ctx, &pb.TaskRequest{TaskId: "task-2", TaskType: "compute", Payload: []byte("2+2")}); err != context.DeadlineExceeded {
+		t.Errorf("task during blob fetch = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	<-fetching
+}
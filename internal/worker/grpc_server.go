@@ -2,24 +2,74 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
 	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/mediatype"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/transfer"
 	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type WorkerServer struct {
 	pb.UnimplementedWorkerServiceServer
 	workerID    string
 	activeTasks int32
+	config      *config.WorkerNodeConfig
+	// slots limits concurrent tasks when concurrency is configured
+	slots chan struct{}
+	// transfers holds streamed payloads and results
+	transfers *transfers
+	// blobs caches the blobs payloads refer to
+	blobs *blobCache
 }
 
-func NewWorkerServer(workerID string) *WorkerServer {
-	return &WorkerServer{
-		workerID:    workerID,
+func NewWorkerServer(config *config.WorkerNodeConfig) *WorkerServer {
+	server := &WorkerServer{
+		workerID:    config.ID,
 		activeTasks: 0,
+		config:      config,
+		transfers:   newTransfers(),
+		blobs:       newBlobCache(config.GetBlobCacheDir(), config.GetBlobCacheSize()),
+	}
+
+	if config.Concurrency > 0 {
+		server.slots = make(chan struct{}, config.Concurrency)
+	}
+
+	return server
+}
+
+// Close stops dropping expired transfers in the background. Call it once
+// the gRPC server has stopped.
+func (s *WorkerServer) Close() {
+	s.transfers.close()
+}
+
+// acquire waits for a free task slot, giving up when the request is cancelled.
+func (s *WorkerServer) acquire(ctx context.Context) error {
+	if s.slots == nil {
+		return nil
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *WorkerServer) release() {
+	if s.slots != nil {
+		<-s.slots
 	}
 }
 
@@ -29,31 +79,155 @@ func (s *WorkerServer) ProcessTask(ctx context.Context, req *pb.TaskRequest) (*p
 
 	logger.GetLogger().Infof("Worker %s processing task %s of type %s", s.workerID, req.TaskId, req.TaskType)
 
-	// Simulate task processing
-	time.Sleep(2 * time.Second)
+	// Take the uploaded payload before anything else, so it is not held
+	// until it expires when the task is turned down
+	if req.PayloadUploaded {
+		payload, ok := s.transfers.take(s.transfers.payloads, req.TaskId)
+		if !ok {
+			return nil, status.Errorf(codes.FailedPrecondition, "no payload was uploaded for task %s", req.TaskId)
+		}
+		req.Payload = payload
+	}
+
+	if !s.config.Supports(req.TaskType) {
+		return &pb.TaskResponse{
+			TaskId:    req.TaskId,
+			Success:   false,
+			Error:     fmt.Sprintf("Task type %s is not supported by worker %s", req.TaskType, s.workerID),
+			ErrorCode: pb.ErrorCode_ERROR_CODE_UNSUPPORTED_TASK_TYPE,
+		}, nil
+	}
+
+	// Blob fetches count against the concurrency limit like the work itself
+	if err := s.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	if req.PayloadBlob != "" {
+		payload, err := s.blobs.get(ctx, s.config.GetBlobSource(), req.PayloadBlob)
+		if err != nil {
+			return &pb.TaskResponse{
+				TaskId:    req.TaskId,
+				Success:   false,
+				Error:     fmt.Sprintf("failed to fetch payload blob %s: %v", req.PayloadBlob, err),
+				ErrorCode: pb.ErrorCode_ERROR_CODE_PAYLOAD_UNAVAILABLE,
+			}, nil
+		}
+		req.Payload = payload
+	}
+
+	// Simulate task processing, abandoning the task once the master's
+	// deadline has passed or it gave up on the call
+	select {
+	case <-time.After(s.config.GetHandlerDelay(req.TaskType)):
+	case <-ctx.Done():
+		logger.GetLogger().Warnf("Worker %s abandoned task %s: %v", s.workerID, req.TaskId, ctx.Err())
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	payload, structured, err := decodePayload(req)
+	if err != nil {
+		return &pb.TaskResponse{
+			TaskId:    req.TaskId,
+			Success:   false,
+			Error:     err.Error(),
+			ErrorCode: pb.ErrorCode_ERROR_CODE_INVALID_PAYLOAD,
+		}, nil
+	}
 
 	// Simple task processing logic
-	var result string
+	var result interface{}
 	var success bool = true
 	var errorMsg string
+	var errorCode pb.ErrorCode
 
 	switch req.TaskType {
 	case "compute":
-		result = fmt.Sprintf("Computed result for: %s", req.Payload)
+		result = compute(payload, structured)
 	case "process":
-		result = fmt.Sprintf("Processed data: %s", req.Payload)
+		result = process(payload, structured)
 	default:
 		success = false
 		errorMsg = "Unknown task type"
-		result = ""
+		errorCode = pb.ErrorCode_ERROR_CODE_UNSUPPORTED_TASK_TYPE
 	}
 
-	return &pb.TaskResponse{
-		TaskId:  req.TaskId,
-		Success: success,
-		Result:  result,
-		Error:   errorMsg,
-	}, nil
+	var data []byte
+	var contentType string
+	if success {
+		data, contentType, err = encodeResult(result, structured)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &pb.TaskResponse{
+		TaskId:      req.TaskId,
+		Success:     success,
+		Result:      data,
+		Error:       errorMsg,
+		ErrorCode:   errorCode,
+		ContentType: contentType,
+		Metadata:    map[string]string{"worker_id": s.workerID},
+	}
+
+	// Results too large for one message are downloaded separately
+	if len(data) > s.config.GetChunkSize() {
+		s.transfers.put(s.transfers.results, req.TaskId, data)
+		resp.Result = nil
+		resp.ResultStreamed = true
+		resp.ResultSize = int64(len(data))
+		resp.ResultSha256 = transfer.Checksum(data)
+	}
+
+	return resp, nil
+}
+
+// compute and process return text for a text payload, and a JSON value for
+// the decoded value of a structured one.
+func compute(payload interface{}, structured bool) interface{} {
+	if structured {
+		return map[string]interface{}{"computed": payload}
+	}
+	return fmt.Sprintf("Computed result for: %s", payload)
+}
+
+func process(payload interface{}, structured bool) interface{} {
+	if structured {
+		return map[string]interface{}{"processed": payload}
+	}
+	return fmt.Sprintf("Processed data: %s", payload)
+}
+
+// decodePayload decodes JSON payloads, reporting them as structured, and
+// returns the rest as text, with binary ones summarized.
+func decodePayload(req *pb.TaskRequest) (interface{}, bool, error) {
+	if mediatype.IsJSON(req.ContentType) {
+		var value interface{}
+		if err := json.Unmarshal(req.Payload, &value); err != nil {
+			return nil, false, fmt.Errorf("invalid JSON payload: %w", err)
+		}
+		return value, true, nil
+	}
+
+	if utf8.Valid(req.Payload) {
+		return string(req.Payload), false, nil
+	}
+	return fmt.Sprintf("%d bytes of %s", len(req.Payload), req.ContentType), false, nil
+}
+
+// encodeResult returns structured results as JSON and the rest as text.
+func encodeResult(result interface{}, structured bool) ([]byte, string, error) {
+	if !structured {
+		return []byte(fmt.Sprint(result)), mediatype.Text, nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return data, mediatype.JSON, nil
 }
 
 func (s *WorkerServer) GetStatus(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
@@ -63,5 +237,7 @@ func (s *WorkerServer) GetStatus(ctx context.Context, req *pb.StatusRequest) (*p
 		WorkerId:    s.workerID,
 		Status:      "healthy",
 		ActiveTasks: tasks,
+		TaskTypes:   s.config.TaskTypes,
+		Labels:      s.config.Labels,
 	}, nil
 }
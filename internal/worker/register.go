@@ -0,0 +1,185 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+)
+
+const (
+	registerRetry   = 2 * time.Second
+	registerTimeout = 10 * time.Second
+)
+
+type registration struct {
+	ID        string            `json:"id"`
+	URL       string            `json:"url"`
+	TaskTypes []string          `json:"task_types,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// Registrar adds the worker to the master's pool through its admin API, and
+// takes it out again on shutdown if it was the one that added it.
+type Registrar struct {
+	cfg *config.WorkerNodeConfig
+
+	mu sync.Mutex
+	// added is set once the master created the worker for a registration,
+	// as opposed to already having it, e.g. from its configuration file
+	added bool
+}
+
+func NewRegistrar(cfg *config.WorkerNodeConfig) *Registrar {
+	return &Registrar{cfg: cfg}
+}
+
+// Run registers the worker, retrying failed attempts until one succeeds or
+// ctx is done. It does not register again afterwards, so a worker removed
+// through the admin API stays removed.
+func (r *Registrar) Run(ctx context.Context) {
+	for {
+		err := r.register(ctx)
+		if err == nil {
+			logger.GetLogger().Infof("Registered with master at %s as %s", r.cfg.Master.Address, r.cfg.GetAdvertiseAddress())
+			return
+		}
+		logger.GetLogger().Warnf("Failed to register with master at %s: %v", r.cfg.Master.Address, err)
+
+		select {
+		case <-time.After(registerRetry):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// register adds the worker to the pool once. A worker the master already
+// has under the same ID counts as registered, even at another address: the
+// master's own configuration for it wins.
+func (r *Registrar) register(ctx context.Context) error {
+	body, _ := json.Marshal(registration{
+		ID:        r.cfg.ID,
+		URL:       r.cfg.GetAdvertiseAddress(),
+		TaskTypes: r.cfg.TaskTypes,
+		Labels:    r.cfg.Labels,
+	})
+
+	status, err := send(ctx, http.MethodPost, r.url("/workers"), body)
+	var statusErr *statusError
+	switch {
+	case err == nil:
+		if status == http.StatusCreated {
+			r.mu.Lock()
+			r.added = true
+			r.mu.Unlock()
+		}
+		return nil
+	case errors.As(err, &statusErr) && statusErr.status == http.StatusConflict:
+		addr, found, listErr := r.poolAddress(ctx)
+		if listErr != nil || !found {
+			// The address is taken by another worker
+			return err
+		}
+		if addr != r.cfg.GetAdvertiseAddress() {
+			logger.GetLogger().Debugf("Master already has worker %s at %s, not %s", r.cfg.ID, addr, r.cfg.GetAdvertiseAddress())
+		}
+		return nil
+	}
+	return err
+}
+
+// poolAddress looks the worker's ID up in the master's pool.
+func (r *Registrar) poolAddress(ctx context.Context) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, registerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url("/workers"), nil)
+	if err != nil {
+		return "", false, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Workers []registration `json:"workers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return "", false, err
+	}
+	for _, worker := range list.Workers {
+		if worker.ID == r.cfg.ID {
+			return worker.URL, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Deregister removes the worker from the master's pool, if registering added
+// it there. Workers the master had before, e.g. from its configuration file,
+// are left in place.
+func (r *Registrar) Deregister() error {
+	r.mu.Lock()
+	added := r.added
+	r.mu.Unlock()
+	if !added {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), registerTimeout)
+	defer cancel()
+
+	_, err := send(ctx, http.MethodDelete, r.url("/workers/"+url.PathEscape(r.cfg.ID)), nil)
+	return err
+}
+
+func (r *Registrar) url(path string) string {
+	return fmt.Sprintf("http://%s/v1%s", r.cfg.Master.Address, path)
+}
+
+// statusError is a response from the master with an error status.
+type statusError struct {
+	status int
+	msg    string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("master answered %d %s: %s", e.status, http.StatusText(e.status), e.msg)
+}
+
+// send makes a request to the master's admin API and returns the status of
+// a successful response.
+func send(ctx context.Context, method, url string, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, registerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, &statusError{status: resp.StatusCode, msg: string(bytes.TrimSpace(msg))}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+)
+
+// fakeMaster answers worker registrations with a fixed status and records
+// the requests it gets.
+type fakeMaster struct {
+	status int
+	pool   []registration
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (m *fakeMaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	m.requests = append(m.requests, r.Method+" "+r.URL.Path)
+	m.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/workers":
+		w.WriteHeader(m.status)
+		w.Write([]byte(`{"error": "worker already exists", "code": "conflict"}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/workers":
+		json.NewEncoder(w).Encode(map[string]interface{}{"workers": m.pool})
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/workers/"):
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (m *fakeMaster) deleted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, req := range m.requests {
+		if req == "DELETE /v1/workers/worker-1" {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestRegistrar(t *testing.T, master *fakeMaster) *Registrar {
+	t.Helper()
+
+	server := httptest.NewServer(master)
+	t.Cleanup(server.Close)
+
+	cfg := config.DefaultWorkerNodeConfig()
+	cfg.ID = "worker-1"
+	cfg.Master.Address = server.Listener.Addr().String()
+	cfg.Master.Advertise = "worker-host:50051"
+	return NewRegistrar(cfg)
+}
+
+func TestRegistrarDeregistersWorkerItAdded(t *testing.T) {
+	master := &fakeMaster{status: http.StatusCreated}
+	r := newTestRegistrar(t, master)
+
+	if err := r.register(context.Background()); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := r.Deregister(); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if !master.deleted() {
+		t.Error("worker added by registering was not deregistered")
+	}
+}
+
+func TestRegistrarKeepsConfiguredWorker(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		// Listed at the same address
+		{"same address", http.StatusOK},
+		// Listed at another address, e.g. localhost in the master's file
+		{"other address", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master := &fakeMaster{
+				status: tt.status,
+				pool:   []registration{{ID: "worker-1", URL: "localhost:50051"}},
+			}
+			r := newTestRegistrar(t, master)
+
+			if err := r.register(context.Background()); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			if err := r.Deregister(); err != nil {
+				t.Fatalf("Deregister: %v", err)
+			}
+			if master.deleted() {
+				t.Error("Deregister removed a worker the master already had")
+			}
+		})
+	}
+}
+
+func TestRegistrarAddressTakenByAnotherWorker(t *testing.T) {
+	master := &fakeMaster{
+		status: http.StatusConflict,
+		pool:   []registration{{ID: "worker-2", URL: "worker-host:50051"}},
+	}
+	r := newTestRegistrar(t, master)
+
+	if err := r.register(context.Background()); err == nil {
+		t.Fatal("register succeeded although the address belongs to worker-2")
+	}
+}
+
+func TestRegistrarRunRegistersOnce(t *testing.T) {
+	master := &fakeMaster{status: http.StatusCreated}
+	r := newTestRegistrar(t, master)
+
+	done := make(chan struct{})
+	go func() {
+		r.Run(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run still running after registering")
+	}
+
+	master.mu.Lock()
+	defer master.mu.Unlock()
+	if len(master.requests) != 1 {
+		t.Errorf("got requests %v, want a single registration", master.requests)
+	}
+}
@@ -0,0 +1,172 @@
+package worker
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/logger"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/transfer"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// transferTTL is how long an uploaded payload waits for its ProcessTask call,
+// and a streamed result for its download, before it is dropped.
+const transferTTL = 5 * time.Minute
+
+// sweepInterval is how often expired transfers are dropped.
+const sweepInterval = 30 * time.Second
+
+// ServerOptions returns the gRPC server options for the worker's message size
+// limit.
+func ServerOptions(cfg *config.WorkerNodeConfig) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.GetMaxMessageSize()),
+		grpc.MaxSendMsgSize(cfg.GetMaxMessageSize()),
+	}
+}
+
+// transfers holds uploaded payloads until their task is processed, and
+// results too large for a response until they are downloaded, by task ID.
+type transfers struct {
+	mu       sync.Mutex
+	payloads map[string]*held
+	results  map[string]*held
+	done     chan struct{}
+}
+
+type held struct {
+	data    []byte
+	expires time.Time
+}
+
+// newTransfers starts dropping expired transfers every sweepInterval until
+// close is called.
+func newTransfers() *transfers {
+	t := &transfers{
+		payloads: make(map[string]*held),
+		results:  make(map[string]*held),
+		done:     make(chan struct{}),
+	}
+	go t.sweepEvery(sweepInterval)
+	return t
+}
+
+func (t *transfers) put(m map[string]*held, taskID string, data []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	m[taskID] = &held{data: data, expires: time.Now().Add(transferTTL)}
+}
+
+func (t *transfers) sweepEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			t.sweep(now)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// sweep drops the transfers that expired by now.
+func (t *transfers) sweep(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, m := range []map[string]*held{t.payloads, t.results} {
+		for id, h := range m {
+			if now.After(h.expires) {
+				delete(m, id)
+			}
+		}
+	}
+}
+
+func (t *transfers) close() {
+	close(t.done)
+}
+
+// take removes and returns what is held for the task.
+func (t *transfers) take(m map[string]*held, taskID string) ([]byte, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := m[taskID]
+	if !ok || time.Now().After(h.expires) {
+		delete(m, taskID)
+		return nil, false
+	}
+	delete(m, taskID)
+	return h.data, true
+}
+
+// UploadPayload receives a payload in chunks ahead of the ProcessTask call
+// that names it. The first chunk announces the task, size and checksum.
+func (s *WorkerServer) UploadPayload(stream pb.WorkerService_UploadPayloadServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if first.TaskId == "" {
+		return status.Error(codes.InvalidArgument, "the first chunk must name the task")
+	}
+
+	assembler, err := transfer.NewAssembler(first.Size, first.Sha256)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	for chunk := first; ; {
+		if err := assembler.Write(chunk.Data); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		chunk, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := assembler.Bytes()
+	if err != nil {
+		return status.Error(codes.DataLoss, err.Error())
+	}
+
+	s.transfers.put(s.transfers.payloads, first.TaskId, data)
+	logger.GetLogger().Infof("Worker %s received a %d byte payload for task %s", s.workerID, len(data), first.TaskId)
+
+	return stream.SendAndClose(&pb.UploadPayloadResponse{
+		TaskId: first.TaskId,
+		Size:   int64(len(data)),
+	})
+}
+
+// DownloadResult streams a result that was too large for its ProcessTask
+// response. A result can be downloaded once.
+func (s *WorkerServer) DownloadResult(req *pb.DownloadResultRequest, stream pb.WorkerService_DownloadResultServer) error {
+	data, ok := s.transfers.take(s.transfers.results, req.TaskId)
+	if !ok {
+		return status.Errorf(codes.NotFound, "no result is waiting for task %s", req.TaskId)
+	}
+
+	for _, chunk := range transfer.Split(data, s.config.GetChunkSize()) {
+		if err := stream.Send(&pb.ResultChunk{Data: chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/config"
+	pb "github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pb"
+)
+
+func newTestWorkerServer(t *testing.T) *WorkerServer {
+	t.Helper()
+
+	cfg := config.DefaultWorkerNodeConfig()
+	cfg.ID = "worker-1"
+	cfg.TaskTypes = []string{"text"}
+	cfg.BlobCache.Dir = t.TempDir()
+	s := NewWorkerServer(cfg)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func heldCount(t *transfers, m map[string]*held) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(m)
+}
+
+func TestProcessTaskDropsUploadForUnsupportedType(t *testing.T) {
+	s := newTestWorkerServer(t)
+	s.transfers.put(s.transfers.payloads, "task-1", []byte("payload"))
+
+	resp, err := s.ProcessTask(context.Background(), &pb.TaskRequest{
+		TaskId:          "task-1",
+		TaskType:        "image",
+		PayloadUploaded: true,
+	})
+	if err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+	if resp.ErrorCode != pb.ErrorCode_ERROR_CODE_UNSUPPORTED_TASK_TYPE {
+		t.Errorf("got error code %v, want %v", resp.ErrorCode, pb.ErrorCode_ERROR_CODE_UNSUPPORTED_TASK_TYPE)
+	}
+	if n := heldCount(s.transfers, s.transfers.payloads); n != 0 {
+		t.Errorf("%d uploaded payloads still held", n)
+	}
+}
+
+func TestSweepDropsExpiredTransfers(t *testing.T) {
+	s := newTestWorkerServer(t)
+	s.transfers.put(s.transfers.payloads, "task-1", []byte("payload"))
+	s.transfers.put(s.transfers.results, "task-2", []byte("result"))
+
+	s.transfers.sweep(time.Now())
+	if n := heldCount(s.transfers, s.transfers.payloads) + heldCount(s.transfers, s.transfers.results); n != 2 {
+		t.Fatalf("sweep before expiry left %d transfers, want 2", n)
+	}
+
+	s.transfers.sweep(time.Now().Add(transferTTL + time.Second))
+	if n := heldCount(s.transfers, s.transfers.payloads) + heldCount(s.transfers, s.transfers.results); n != 0 {
+		t.Errorf("sweep after expiry left %d transfers, want 0", n)
+	}
+}
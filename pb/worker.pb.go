@@ -21,13 +21,72 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_CODE_UNSPECIFIED           ErrorCode = 0
+	ErrorCode_ERROR_CODE_TASK_FAILED           ErrorCode = 1
+	ErrorCode_ERROR_CODE_INVALID_PAYLOAD       ErrorCode = 2
+	ErrorCode_ERROR_CODE_UNSUPPORTED_TASK_TYPE ErrorCode = 3
+	ErrorCode_ERROR_CODE_PAYLOAD_UNAVAILABLE   ErrorCode = 4
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0: "ERROR_CODE_UNSPECIFIED",
+		1: "ERROR_CODE_TASK_FAILED",
+		2: "ERROR_CODE_INVALID_PAYLOAD",
+		3: "ERROR_CODE_UNSUPPORTED_TASK_TYPE",
+		4: "ERROR_CODE_PAYLOAD_UNAVAILABLE",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_CODE_UNSPECIFIED":           0,
+		"ERROR_CODE_TASK_FAILED":           1,
+		"ERROR_CODE_INVALID_PAYLOAD":       2,
+		"ERROR_CODE_UNSUPPORTED_TASK_TYPE": 3,
+		"ERROR_CODE_PAYLOAD_UNAVAILABLE":   4,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_worker_proto_enumTypes[0].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_proto_worker_proto_enumTypes[0]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_worker_proto_rawDescGZIP(), []int{0}
+}
+
 type TaskRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	TaskType      string                 `protobuf:"bytes,2,opt,name=task_type,json=taskType,proto3" json:"task_type,omitempty"`
-	Payload       string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TaskId          string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	TaskType        string                 `protobuf:"bytes,2,opt,name=task_type,json=taskType,proto3" json:"task_type,omitempty"`
+	Payload         []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	ContentType     string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Metadata        map[string]string      `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	PayloadUploaded bool                   `protobuf:"varint,6,opt,name=payload_uploaded,json=payloadUploaded,proto3" json:"payload_uploaded,omitempty"`
+	PayloadBlob     string                 `protobuf:"bytes,7,opt,name=payload_blob,json=payloadBlob,proto3" json:"payload_blob,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *TaskRequest) Reset() {
@@ -74,21 +133,55 @@ func (x *TaskRequest) GetTaskType() string {
 	return ""
 }
 
-func (x *TaskRequest) GetPayload() string {
+func (x *TaskRequest) GetPayload() []byte {
 	if x != nil {
 		return x.Payload
 	}
+	return nil
+}
+
+func (x *TaskRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *TaskRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *TaskRequest) GetPayloadUploaded() bool {
+	if x != nil {
+		return x.PayloadUploaded
+	}
+	return false
+}
+
+func (x *TaskRequest) GetPayloadBlob() string {
+	if x != nil {
+		return x.PayloadBlob
+	}
 	return ""
 }
 
 type TaskResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	Result        string                 `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
-	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TaskId         string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Success        bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Result         []byte                 `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	Error          string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	ContentType    string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Metadata       map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	ResultStreamed bool                   `protobuf:"varint,7,opt,name=result_streamed,json=resultStreamed,proto3" json:"result_streamed,omitempty"`
+	ResultSize     int64                  `protobuf:"varint,8,opt,name=result_size,json=resultSize,proto3" json:"result_size,omitempty"`
+	ResultSha256   string                 `protobuf:"bytes,9,opt,name=result_sha256,json=resultSha256,proto3" json:"result_sha256,omitempty"`
+	ErrorCode      ErrorCode              `protobuf:"varint,10,opt,name=error_code,json=errorCode,proto3,enum=worker.ErrorCode" json:"error_code,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *TaskResponse) Reset() {
@@ -135,11 +228,11 @@ func (x *TaskResponse) GetSuccess() bool {
 	return false
 }
 
-func (x *TaskResponse) GetResult() string {
+func (x *TaskResponse) GetResult() []byte {
 	if x != nil {
 		return x.Result
 	}
-	return ""
+	return nil
 }
 
 func (x *TaskResponse) GetError() string {
@@ -149,6 +242,256 @@ func (x *TaskResponse) GetError() string {
 	return ""
 }
 
+func (x *TaskResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *TaskResponse) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *TaskResponse) GetResultStreamed() bool {
+	if x != nil {
+		return x.ResultStreamed
+	}
+	return false
+}
+
+func (x *TaskResponse) GetResultSize() int64 {
+	if x != nil {
+		return x.ResultSize
+	}
+	return 0
+}
+
+func (x *TaskResponse) GetResultSha256() string {
+	if x != nil {
+		return x.ResultSha256
+	}
+	return ""
+}
+
+func (x *TaskResponse) GetErrorCode() ErrorCode {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+type PayloadChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PayloadChunk) Reset() {
+	*x = PayloadChunk{}
+	mi := &file_proto_worker_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PayloadChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PayloadChunk) ProtoMessage() {}
+
+func (x *PayloadChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_worker_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PayloadChunk.ProtoReflect.Descriptor instead.
+func (*PayloadChunk) Descriptor() ([]byte, []int) {
+	return file_proto_worker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PayloadChunk) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *PayloadChunk) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *PayloadChunk) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *PayloadChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type UploadPayloadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadPayloadResponse) Reset() {
+	*x = UploadPayloadResponse{}
+	mi := &file_proto_worker_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadPayloadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadPayloadResponse) ProtoMessage() {}
+
+func (x *UploadPayloadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_worker_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadPayloadResponse.ProtoReflect.Descriptor instead.
+func (*UploadPayloadResponse) Descriptor() ([]byte, []int) {
+	return file_proto_worker_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UploadPayloadResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *UploadPayloadResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type DownloadResultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadResultRequest) Reset() {
+	*x = DownloadResultRequest{}
+	mi := &file_proto_worker_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadResultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadResultRequest) ProtoMessage() {}
+
+func (x *DownloadResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_worker_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadResultRequest.ProtoReflect.Descriptor instead.
+func (*DownloadResultRequest) Descriptor() ([]byte, []int) {
+	return file_proto_worker_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DownloadResultRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type ResultChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultChunk) Reset() {
+	*x = ResultChunk{}
+	mi := &file_proto_worker_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultChunk) ProtoMessage() {}
+
+func (x *ResultChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_worker_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultChunk.ProtoReflect.Descriptor instead.
+func (*ResultChunk) Descriptor() ([]byte, []int) {
+	return file_proto_worker_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ResultChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
 type StatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
@@ -158,7 +501,7 @@ type StatusRequest struct {
 
 func (x *StatusRequest) Reset() {
 	*x = StatusRequest{}
-	mi := &file_proto_worker_proto_msgTypes[2]
+	mi := &file_proto_worker_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -170,7 +513,7 @@ func (x *StatusRequest) String() string {
 func (*StatusRequest) ProtoMessage() {}
 
 func (x *StatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_worker_proto_msgTypes[2]
+	mi := &file_proto_worker_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -183,7 +526,7 @@ func (x *StatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
 func (*StatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_worker_proto_rawDescGZIP(), []int{2}
+	return file_proto_worker_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *StatusRequest) GetWorkerId() string {
@@ -198,13 +541,15 @@ type StatusResponse struct {
 	WorkerId      string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
 	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
 	ActiveTasks   int32                  `protobuf:"varint,3,opt,name=active_tasks,json=activeTasks,proto3" json:"active_tasks,omitempty"`
+	TaskTypes     []string               `protobuf:"bytes,4,rep,name=task_types,json=taskTypes,proto3" json:"task_types,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StatusResponse) Reset() {
 	*x = StatusResponse{}
-	mi := &file_proto_worker_proto_msgTypes[3]
+	mi := &file_proto_worker_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -216,7 +561,7 @@ func (x *StatusResponse) String() string {
 func (*StatusResponse) ProtoMessage() {}
 
 func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_worker_proto_msgTypes[3]
+	mi := &file_proto_worker_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -229,7 +574,7 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_worker_proto_rawDescGZIP(), []int{3}
+	return file_proto_worker_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *StatusResponse) GetWorkerId() string {
@@ -253,29 +598,88 @@ func (x *StatusResponse) GetActiveTasks() int32 {
 	return 0
 }
 
+func (x *StatusResponse) GetTaskTypes() []string {
+	if x != nil {
+		return x.TaskTypes
+	}
+	return nil
+}
+
+func (x *StatusResponse) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 var File_proto_worker_proto protoreflect.FileDescriptor
 
 const file_proto_worker_proto_rawDesc = "" +
 	"\n" +
-	"\x12proto/worker.proto\x12\x06worker\"]\n" +
+	"\x12proto/worker.proto\x12\x06worker\"\xca\x02\n" +
 	"\vTaskRequest\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1b\n" +
 	"\ttask_type\x18\x02 \x01(\tR\btaskType\x12\x18\n" +
-	"\apayload\x18\x03 \x01(\tR\apayload\"o\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12=\n" +
+	"\bmetadata\x18\x05 \x03(\v2!.worker.TaskRequest.MetadataEntryR\bmetadata\x12)\n" +
+	"\x10payload_uploaded\x18\x06 \x01(\bR\x0fpayloadUploaded\x12!\n" +
+	"\fpayload_blob\x18\a \x01(\tR\vpayloadBlob\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb0\x03\n" +
 	"\fTaskResponse\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x16\n" +
-	"\x06result\x18\x03 \x01(\tR\x06result\x12\x14\n" +
-	"\x05error\x18\x04 \x01(\tR\x05error\",\n" +
+	"\x06result\x18\x03 \x01(\fR\x06result\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12>\n" +
+	"\bmetadata\x18\x06 \x03(\v2\".worker.TaskResponse.MetadataEntryR\bmetadata\x12'\n" +
+	"\x0fresult_streamed\x18\a \x01(\bR\x0eresultStreamed\x12\x1f\n" +
+	"\vresult_size\x18\b \x01(\x03R\n" +
+	"resultSize\x12#\n" +
+	"\rresult_sha256\x18\t \x01(\tR\fresultSha256\x120\n" +
+	"\n" +
+	"error_code\x18\n" +
+	" \x01(\x0e2\x11.worker.ErrorCodeR\terrorCode\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"g\n" +
+	"\fPayloadChunk\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\fR\x04data\"D\n" +
+	"\x15UploadPayloadResponse\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\"0\n" +
+	"\x15DownloadResultRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"!\n" +
+	"\vResultChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\",\n" +
 	"\rStatusRequest\x12\x1b\n" +
-	"\tworker_id\x18\x01 \x01(\tR\bworkerId\"h\n" +
+	"\tworker_id\x18\x01 \x01(\tR\bworkerId\"\xfe\x01\n" +
 	"\x0eStatusResponse\x12\x1b\n" +
 	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12\x16\n" +
 	"\x06status\x18\x02 \x01(\tR\x06status\x12!\n" +
-	"\factive_tasks\x18\x03 \x01(\x05R\vactiveTasks2\x85\x01\n" +
+	"\factive_tasks\x18\x03 \x01(\x05R\vactiveTasks\x12\x1d\n" +
+	"\n" +
+	"task_types\x18\x04 \x03(\tR\ttaskTypes\x12:\n" +
+	"\x06labels\x18\x05 \x03(\v2\".worker.StatusResponse.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01*\xad\x01\n" +
+	"\tErrorCode\x12\x1a\n" +
+	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16ERROR_CODE_TASK_FAILED\x10\x01\x12\x1e\n" +
+	"\x1aERROR_CODE_INVALID_PAYLOAD\x10\x02\x12$\n" +
+	" ERROR_CODE_UNSUPPORTED_TASK_TYPE\x10\x03\x12\"\n" +
+	"\x1eERROR_CODE_PAYLOAD_UNAVAILABLE\x10\x042\x95\x02\n" +
 	"\rWorkerService\x128\n" +
 	"\vProcessTask\x12\x13.worker.TaskRequest\x1a\x14.worker.TaskResponse\x12:\n" +
-	"\tGetStatus\x12\x15.worker.StatusRequest\x1a\x16.worker.StatusResponseB\x06Z\x04./pbb\x06proto3"
+	"\tGetStatus\x12\x15.worker.StatusRequest\x1a\x16.worker.StatusResponse\x12F\n" +
+	"\rUploadPayload\x12\x14.worker.PayloadChunk\x1a\x1d.worker.UploadPayloadResponse(\x01\x12F\n" +
+	"\x0eDownloadResult\x12\x1d.worker.DownloadResultRequest\x1a\x13.worker.ResultChunk0\x01B\x06Z\x04./pbb\x06proto3"
 
 var (
 	file_proto_worker_proto_rawDescOnce sync.Once
@@ -289,23 +693,40 @@ func file_proto_worker_proto_rawDescGZIP() []byte {
 	return file_proto_worker_proto_rawDescData
 }
 
-var file_proto_worker_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_worker_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_worker_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_proto_worker_proto_goTypes = []any{
-	(*TaskRequest)(nil),    // 0: worker.TaskRequest
-	(*TaskResponse)(nil),   // 1: worker.TaskResponse
-	(*StatusRequest)(nil),  // 2: worker.StatusRequest
-	(*StatusResponse)(nil), // 3: worker.StatusResponse
+	(ErrorCode)(0),                // 0: worker.ErrorCode
+	(*TaskRequest)(nil),           // 1: worker.TaskRequest
+	(*TaskResponse)(nil),          // 2: worker.TaskResponse
+	(*PayloadChunk)(nil),          // 3: worker.PayloadChunk
+	(*UploadPayloadResponse)(nil), // 4: worker.UploadPayloadResponse
+	(*DownloadResultRequest)(nil), // 5: worker.DownloadResultRequest
+	(*ResultChunk)(nil),           // 6: worker.ResultChunk
+	(*StatusRequest)(nil),         // 7: worker.StatusRequest
+	(*StatusResponse)(nil),        // 8: worker.StatusResponse
+	nil,                           // 9: worker.TaskRequest.MetadataEntry
+	nil,                           // 10: worker.TaskResponse.MetadataEntry
+	nil,                           // 11: worker.StatusResponse.LabelsEntry
 }
 var file_proto_worker_proto_depIdxs = []int32{
-	0, // 0: worker.WorkerService.ProcessTask:input_type -> worker.TaskRequest
-	2, // 1: worker.WorkerService.GetStatus:input_type -> worker.StatusRequest
-	1, // 2: worker.WorkerService.ProcessTask:output_type -> worker.TaskResponse
-	3, // 3: worker.WorkerService.GetStatus:output_type -> worker.StatusResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	9,  // 0: worker.TaskRequest.metadata:type_name -> worker.TaskRequest.MetadataEntry
+	10, // 1: worker.TaskResponse.metadata:type_name -> worker.TaskResponse.MetadataEntry
+	0,  // 2: worker.TaskResponse.error_code:type_name -> worker.ErrorCode
+	11, // 3: worker.StatusResponse.labels:type_name -> worker.StatusResponse.LabelsEntry
+	1,  // 4: worker.WorkerService.ProcessTask:input_type -> worker.TaskRequest
+	7,  // 5: worker.WorkerService.GetStatus:input_type -> worker.StatusRequest
+	3,  // 6: worker.WorkerService.UploadPayload:input_type -> worker.PayloadChunk
+	5,  // 7: worker.WorkerService.DownloadResult:input_type -> worker.DownloadResultRequest
+	2,  // 8: worker.WorkerService.ProcessTask:output_type -> worker.TaskResponse
+	8,  // 9: worker.WorkerService.GetStatus:output_type -> worker.StatusResponse
+	4,  // 10: worker.WorkerService.UploadPayload:output_type -> worker.UploadPayloadResponse
+	6,  // 11: worker.WorkerService.DownloadResult:output_type -> worker.ResultChunk
+	8,  // [8:12] is the sub-list for method output_type
+	4,  // [4:8] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_proto_worker_proto_init() }
@@ -318,13 +739,14 @@ func file_proto_worker_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_worker_proto_rawDesc), len(file_proto_worker_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_worker_proto_goTypes,
 		DependencyIndexes: file_proto_worker_proto_depIdxs,
+		EnumInfos:         file_proto_worker_proto_enumTypes,
 		MessageInfos:      file_proto_worker_proto_msgTypes,
 	}.Build()
 	File_proto_worker_proto = out.File
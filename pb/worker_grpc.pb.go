@@ -19,8 +19,10 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	WorkerService_ProcessTask_FullMethodName = "/worker.WorkerService/ProcessTask"
-	WorkerService_GetStatus_FullMethodName   = "/worker.WorkerService/GetStatus"
+	WorkerService_ProcessTask_FullMethodName    = "/worker.WorkerService/ProcessTask"
+	WorkerService_GetStatus_FullMethodName      = "/worker.WorkerService/GetStatus"
+	WorkerService_UploadPayload_FullMethodName  = "/worker.WorkerService/UploadPayload"
+	WorkerService_DownloadResult_FullMethodName = "/worker.WorkerService/DownloadResult"
 )
 
 // WorkerServiceClient is the client API for WorkerService service.
@@ -29,6 +31,8 @@ const (
 type WorkerServiceClient interface {
 	ProcessTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskResponse, error)
 	GetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	UploadPayload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PayloadChunk, UploadPayloadResponse], error)
+	DownloadResult(ctx context.Context, in *DownloadResultRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResultChunk], error)
 }
 
 type workerServiceClient struct {
@@ -59,12 +63,46 @@ func (c *workerServiceClient) GetStatus(ctx context.Context, in *StatusRequest,
 	return out, nil
 }
 
+func (c *workerServiceClient) UploadPayload(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PayloadChunk, UploadPayloadResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WorkerService_ServiceDesc.Streams[0], WorkerService_UploadPayload_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PayloadChunk, UploadPayloadResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WorkerService_UploadPayloadClient = grpc.ClientStreamingClient[PayloadChunk, UploadPayloadResponse]
+
+func (c *workerServiceClient) DownloadResult(ctx context.Context, in *DownloadResultRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResultChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WorkerService_ServiceDesc.Streams[1], WorkerService_DownloadResult_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadResultRequest, ResultChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WorkerService_DownloadResultClient = grpc.ServerStreamingClient[ResultChunk]
+
 // WorkerServiceServer is the server API for WorkerService service.
 // All implementations must embed UnimplementedWorkerServiceServer
 // for forward compatibility.
 type WorkerServiceServer interface {
 	ProcessTask(context.Context, *TaskRequest) (*TaskResponse, error)
 	GetStatus(context.Context, *StatusRequest) (*StatusResponse, error)
+	UploadPayload(grpc.ClientStreamingServer[PayloadChunk, UploadPayloadResponse]) error
+	DownloadResult(*DownloadResultRequest, grpc.ServerStreamingServer[ResultChunk]) error
 	mustEmbedUnimplementedWorkerServiceServer()
 }
 
@@ -81,6 +119,12 @@ func (UnimplementedWorkerServiceServer) ProcessTask(context.Context, *TaskReques
 func (UnimplementedWorkerServiceServer) GetStatus(context.Context, *StatusRequest) (*StatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
 }
+func (UnimplementedWorkerServiceServer) UploadPayload(grpc.ClientStreamingServer[PayloadChunk, UploadPayloadResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadPayload not implemented")
+}
+func (UnimplementedWorkerServiceServer) DownloadResult(*DownloadResultRequest, grpc.ServerStreamingServer[ResultChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadResult not implemented")
+}
 func (UnimplementedWorkerServiceServer) mustEmbedUnimplementedWorkerServiceServer() {}
 func (UnimplementedWorkerServiceServer) testEmbeddedByValue()                       {}
 
@@ -138,6 +182,24 @@ func _WorkerService_GetStatus_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkerService_UploadPayload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WorkerServiceServer).UploadPayload(&grpc.GenericServerStream[PayloadChunk, UploadPayloadResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WorkerService_UploadPayloadServer = grpc.ClientStreamingServer[PayloadChunk, UploadPayloadResponse]
+
+func _WorkerService_DownloadResult_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadResultRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WorkerServiceServer).DownloadResult(m, &grpc.GenericServerStream[DownloadResultRequest, ResultChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WorkerService_DownloadResultServer = grpc.ServerStreamingServer[ResultChunk]
+
 // WorkerService_ServiceDesc is the grpc.ServiceDesc for WorkerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -154,6 +216,17 @@ var WorkerService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _WorkerService_GetStatus_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadPayload",
+			Handler:       _WorkerService_UploadPayload_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadResult",
+			Handler:       _WorkerService_DownloadResult_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "proto/worker.proto",
 }
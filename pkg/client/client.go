@@ -0,0 +1,432 @@
+// Package client is a Go client for the master's REST API.
+//
+//	c, err := client.New("http://localhost:8080")
+//	task, err := c.SubmitAndWait(ctx, client.TaskRequest{TaskType: "compute", Payload: "2+2"})
+//
+// Requests that fail with a network error or a temporary status such as 503
+// are retried with exponential backoff. Submissions carry an idempotency key,
+// so a retried submission never runs the task twice.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultRetries      = 3
+	DefaultBackoff      = 200 * time.Millisecond
+	DefaultPollInterval = 500 * time.Millisecond
+	maxBackoff          = 5 * time.Second
+	maxEventSize        = 4 * 1024 * 1024
+	// apiPrefix is the version of the master's API the client speaks
+	apiPrefix = "/v1"
+)
+
+// Client talks to a master. It is safe for concurrent use.
+type Client struct {
+	baseURL      string
+	http         *http.Client
+	retries      int
+	backoff      time.Duration
+	pollInterval time.Duration
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithRetries sets how many times a failed request is retried and the delay
+// before the first retry, which doubles on every further one. Zero retries
+// disables retrying.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// WithPollInterval sets how often Wait checks on a task.
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.pollInterval = interval
+	}
+}
+
+// New returns a client for the master at baseURL, such as
+// "http://localhost:8080". A URL without a scheme is assumed to be http.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid master URL %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:      strings.TrimRight(u.String(), "/"),
+		http:         &http.Client{Timeout: 30 * time.Second},
+		retries:      DefaultRetries,
+		backoff:      DefaultBackoff,
+		pollInterval: DefaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Submit submits a task and returns once the master has accepted it, in the
+// running or scheduled state. The task holds what the master answered: its
+// ID, state, RunAt and, for a cached result, its outcome. The rest, such as
+// the payload and the timestamps, is only filled in by a later Get.
+func (c *Client) Submit(ctx context.Context, req TaskRequest) (*Task, error) {
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = uuid.New().String()
+	}
+
+	headers := map[string]string{"Idempotency-Key": req.IdempotencyKey}
+
+	var task Task
+	if err := c.do(ctx, http.MethodPost, "/tasks?wait=false", req, headers, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// SubmitAndWait submits a task and waits for it to finish. A task that ran
+// but failed is returned without an error; check its Success field.
+func (c *Client) SubmitAndWait(ctx context.Context, req TaskRequest) (*Task, error) {
+	task, err := c.Submit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.Wait(ctx, task.ID)
+}
+
+// Wait polls a task until it has finished or ctx is done.
+func (c *Client) Wait(ctx context.Context, taskID string) (*Task, error) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		task, err := c.Get(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		if task.State.Finished() {
+			return task, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return task, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) Get(ctx context.Context, taskID string) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodGet, "/tasks/"+url.PathEscape(taskID), nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Cancel cancels a scheduled or running task. Cancelling a finished task
+// fails with ErrConflict.
+func (c *Client) Cancel(ctx context.Context, taskID string) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodPost, "/tasks/"+url.PathEscape(taskID)+"/cancel", nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ListWorkers returns the workers in the master's pool.
+func (c *Client) ListWorkers(ctx context.Context) ([]Worker, error) {
+	var resp struct {
+		Workers []Worker `json:"workers"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/workers", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Workers, nil
+}
+
+// TaskTypes lists the task types declared on the master.
+func (c *Client) TaskTypes(ctx context.Context) ([]TaskType, error) {
+	var resp struct {
+		TaskTypes []TaskType `json:"task_types"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/task-types", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.TaskTypes, nil
+}
+
+// PutBlob stores data on the master and returns the blob, whose Ref can be
+// submitted as a task payload. Storing the same data again returns the
+// existing blob.
+func (c *Client) PutBlob(ctx context.Context, data []byte) (*Blob, error) {
+	var blob Blob
+	headers := map[string]string{"Content-Type": "application/octet-stream"}
+	if err := c.do(ctx, http.MethodPut, "/blobs", rawBody(data), headers, &blob); err != nil {
+		return nil, err
+	}
+	return &blob, nil
+}
+
+// Cordon stops the master from sending new tasks to a worker.
+func (c *Client) Cordon(ctx context.Context, workerID string) (*Worker, error) {
+	return c.cordon(ctx, workerID, "cordon")
+}
+
+// Uncordon lets the master send tasks to a cordoned worker again.
+func (c *Client) Uncordon(ctx context.Context, workerID string) (*Worker, error) {
+	return c.cordon(ctx, workerID, "uncordon")
+}
+
+func (c *Client) cordon(ctx context.Context, workerID, action string) (*Worker, error) {
+	var worker Worker
+	if err := c.do(ctx, http.MethodPost, "/workers/"+url.PathEscape(workerID)+"/"+action, nil, nil, &worker); err != nil {
+		return nil, err
+	}
+	return &worker, nil
+}
+
+// Events follows the master's task events, calling handle with each task as
+// its state changes. It returns when ctx is done, the master closes the
+// stream or handle returns an error, which Events then returns.
+func (c *Client) Events(ctx context.Context, handle func(*Task) error) error {
+	stream, err := c.Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	return stream.Follow(handle)
+}
+
+// EventStream is an open subscription to the master's task events.
+type EventStream struct {
+	ctx     context.Context
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// Subscribe opens the master's task event stream. Every state change after
+// Subscribe returns is delivered, so a task looked up afterwards cannot
+// finish unseen. The stream must be closed.
+func (c *Client) Subscribe(ctx context.Context) (*EventStream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+apiPrefix+"/events", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The stream stays open far longer than any request timeout
+	httpClient := *c.http
+	httpClient.Timeout = 0
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, apiError(resp.StatusCode, data)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxEventSize)
+	return &EventStream{ctx: ctx, body: resp.Body, scanner: scanner}, nil
+}
+
+// Next waits for the next task event. It returns io.EOF once the master
+// closes the stream, and the context's error once it is done.
+func (s *EventStream) Next() (*Task, error) {
+	for s.scanner.Scan() {
+		data, ok := strings.CutPrefix(s.scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		var task Task
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			return nil, fmt.Errorf("failed to decode event: %w", err)
+		}
+		return &task, nil
+	}
+
+	if s.ctx.Err() != nil {
+		return nil, s.ctx.Err()
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Follow calls handle with each task event until the stream ends, the
+// context is done or handle returns an error, which Follow then returns.
+func (s *EventStream) Follow(handle func(*Task) error) error {
+	for {
+		task, err := s.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := handle(task); err != nil {
+			return err
+		}
+	}
+}
+
+// Close ends the subscription.
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
+
+// WorkerStatus asks a worker, through the master, for its live status.
+func (c *Client) WorkerStatus(ctx context.Context, workerID string) (*WorkerStatus, error) {
+	var status WorkerStatus
+	if err := c.do(ctx, http.MethodGet, "/status/"+url.PathEscape(workerID), nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// do sends a request, retrying network errors and temporary statuses.
+// rawBody is a request body sent as is rather than encoded as JSON.
+type rawBody []byte
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, headers map[string]string, out interface{}) error {
+	var payload []byte
+	switch body := body.(type) {
+	case nil:
+	case rawBody:
+		payload = body
+	default:
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		err := c.once(ctx, method, path, payload, headers, out)
+		if err == nil || attempt >= c.retries || !retryable(ctx, err) {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (c *Client) once(ctx context.Context, method, path string, payload []byte, headers map[string]string, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+apiPrefix+path, body)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// A submitted task that failed comes back with the task itself, which is
+	// a result rather than an error, whatever the status
+	if resp.StatusCode >= 300 && !isTaskBody(data) {
+		return apiError(resp.StatusCode, data)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+func isTaskBody(data []byte) bool {
+	var probe struct {
+		TaskID string `json:"task_id"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.TaskID != ""
+}
+
+func apiError(statusCode int, data []byte) *APIError {
+	var body struct {
+		Error   string       `json:"error"`
+		Code    string       `json:"code"`
+		Details []FieldError `json:"details"`
+	}
+	msg := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		msg = body.Error
+	}
+	return &APIError{StatusCode: statusCode, Code: body.Code, Message: msg, Fields: body.Details}
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.temporary()
+	}
+
+	// Anything else failed before a response arrived
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
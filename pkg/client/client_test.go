@@ -0,0 +1,67 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/internal/testcluster"
+	"github.com/Shariful-NomaD-Islam/ds-with-rest-grpc/pkg/client"
+)
+
+func TestSubscribeBeforeTaskFinishes(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+	c.Worker("worker-1").SetLatency(100 * time.Millisecond)
+	cl := c.Client()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Subscribe returns before any event has been sent
+	stream, err := cl.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer stream.Close()
+
+	task, err := cl.Submit(ctx, client.TaskRequest{TaskType: "compute", Payload: "2+2"})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	for {
+		event, err := stream.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		if event.ID == task.ID && event.State.Finished() {
+			if event.State != client.TaskStateCompleted {
+				t.Errorf("task finished as %s, want completed", event.State)
+			}
+			return
+		}
+	}
+}
+
+func TestSubmitReturnsOnlyWhatTheMasterAnswered(t *testing.T) {
+	c := testcluster.New(t, testcluster.Options{Workers: 1})
+	cl := c.Client()
+
+	task, err := cl.Submit(context.Background(), client.TaskRequest{
+		TaskType:    "compute",
+		Data:        []byte("2+2"),
+		ContentType: "text/plain",
+		Metadata:    map[string]string{"owner": "ops"},
+	})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if task.ID == "" || task.State == "" {
+		t.Errorf("got task %q in state %q, want an ID and a state", task.ID, task.State)
+	}
+	// The request is not echoed back as if the master had returned it
+	if task.PayloadBase64 != nil || task.PayloadJSON != nil || task.Payload != "" || task.Metadata != nil {
+		t.Errorf("Submit returned request fields: payload %q/%s/%q, metadata %v",
+			task.Payload, task.PayloadJSON, task.PayloadBase64, task.Metadata)
+	}
+}
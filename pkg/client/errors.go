@@ -0,0 +1,72 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors matching the master's responses; test for them with errors.Is.
+var (
+	// ErrInvalidRequest: the request was malformed (400).
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrNotFound: the task or worker does not exist (404).
+	ErrNotFound = errors.New("not found")
+	// ErrConflict: the task already finished, the idempotency key belongs
+	// to another task, or the worker already exists (409).
+	ErrConflict = errors.New("conflict")
+	// ErrUnplaceable: no worker accepts the task type or matches the
+	// selector (422).
+	ErrUnplaceable = errors.New("no worker can run the task")
+	// ErrUnavailable: there are no workers, every worker that could run the
+	// task is cordoned, or the master is unavailable (503).
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// APIError is an error response from the master.
+type APIError struct {
+	StatusCode int
+	// Code classifies the error, e.g. unknown_task_type or unavailable.
+	Code    string
+	Message string
+	// Fields lists the violations of a payload that does not match its task
+	// type's schema.
+	Fields []FieldError
+}
+
+// FieldError is a violation of a task type's schema, such as
+// payload.numbers[0] must be a number.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("master returned %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is matches the error against the sentinel errors by status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrUnplaceable:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// temporary reports whether retrying the request may succeed.
+func (e *APIError) temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TaskState is the lifecycle state of a task.
+type TaskState string
+
+const (
+	TaskStateScheduled TaskState = "scheduled"
+	TaskStateRunning   TaskState = "running"
+	TaskStateCompleted TaskState = "completed"
+	TaskStateFailed    TaskState = "failed"
+	TaskStateCancelled TaskState = "cancelled"
+	TaskStateTimedOut  TaskState = "timed_out"
+)
+
+// Finished reports whether the state is final.
+func (s TaskState) Finished() bool {
+	return s == TaskStateCompleted || s == TaskStateFailed || s == TaskStateCancelled || s == TaskStateTimedOut
+}
+
+// TaskRequest describes a task to submit.
+type TaskRequest struct {
+	TaskType string
+	// Payload is a text payload. Data carries binary ones instead, and
+	// Value structured ones, sent as JSON; set only one of the three.
+	Payload string
+	Data    []byte
+	Value   interface{}
+	// ContentType defaults to text/plain for Payload,
+	// application/octet-stream for Data and application/json for Value
+	ContentType string
+	Metadata    map[string]string
+	// RunAt or Delay hold the task back until later; set at most one.
+	RunAt time.Time
+	Delay time.Duration
+	// Timeout bounds how long the task may run once dispatched; the task
+	// type's default applies when it is zero.
+	Timeout time.Duration
+	// Selector restricts the task to workers whose labels match it, e.g.
+	// "zone in (a,b)"; Preferences only rank the workers that do.
+	Selector    string
+	Preferences []Preference
+	// RoutingKey sends tasks sharing the key to the same worker.
+	RoutingKey string
+	// IdempotencyKey makes retries of the submission return the task
+	// created first. Submit generates one when it is empty.
+	IdempotencyKey string
+}
+
+// Preference is a soft placement rule; Weight may be negative.
+type Preference struct {
+	Selector string `json:"selector"`
+	Weight   int    `json:"weight"`
+}
+
+func (r TaskRequest) MarshalJSON() ([]byte, error) {
+	body := struct {
+		TaskType      string            `json:"task_type"`
+		Payload       interface{}       `json:"payload,omitempty"`
+		PayloadBase64 []byte            `json:"payload_base64,omitempty"`
+		ContentType   string            `json:"content_type,omitempty"`
+		Metadata      map[string]string `json:"metadata,omitempty"`
+		RunAt         *time.Time        `json:"run_at,omitempty"`
+		Delay         string            `json:"delay,omitempty"`
+		Timeout       string            `json:"timeout,omitempty"`
+		Selector      string            `json:"selector,omitempty"`
+		Preferences   []Preference      `json:"preferences,omitempty"`
+		RoutingKey    string            `json:"routing_key,omitempty"`
+	}{
+		TaskType:      r.TaskType,
+		PayloadBase64: r.Data,
+		ContentType:   r.ContentType,
+		Metadata:      r.Metadata,
+		Selector:      r.Selector,
+		Preferences:   r.Preferences,
+		RoutingKey:    r.RoutingKey,
+	}
+	if r.Value != nil {
+		body.Payload = r.Value
+	} else if r.Payload != "" {
+		body.Payload = r.Payload
+	}
+	if !r.RunAt.IsZero() {
+		body.RunAt = &r.RunAt
+	}
+	if r.Delay > 0 {
+		body.Delay = r.Delay.String()
+	}
+	if r.Timeout > 0 {
+		body.Timeout = r.Timeout.String()
+	}
+	return json.Marshal(body)
+}
+
+// Task is a submitted task and, once finished, its outcome. JSON payloads
+// and results come in PayloadJSON and ResultJSON, and those that are not
+// valid UTF-8 in PayloadBase64 and ResultBase64, instead of Payload and
+// Result.
+type Task struct {
+	ID                string            `json:"task_id"`
+	TaskType          string            `json:"task_type"`
+	Payload           string            `json:"-"`
+	PayloadJSON       json.RawMessage   `json:"-"`
+	PayloadBase64     []byte            `json:"payload_base64"`
+	PayloadBlob       string            `json:"payload_blob"`
+	ContentType       string            `json:"content_type"`
+	Metadata          map[string]string `json:"metadata"`
+	Selector          string            `json:"selector"`
+	RoutingKey        string            `json:"routing_key"`
+	State             TaskState         `json:"state"`
+	Success           bool              `json:"success"`
+	Result            string            `json:"-"`
+	ResultJSON        json.RawMessage   `json:"-"`
+	ResultBase64      []byte            `json:"result_base64"`
+	ResultContentType string            `json:"result_content_type"`
+	ResultMetadata    map[string]string `json:"result_metadata"`
+	Cached            bool              `json:"cached"`
+	Timeout           string            `json:"timeout"`
+	Error             string            `json:"error"`
+	ErrorCode         string            `json:"error_code"`
+	CreatedAt         time.Time         `json:"created_at"`
+	RunAt             *time.Time        `json:"run_at"`
+	StartedAt         *time.Time        `json:"started_at"`
+	FinishedAt        *time.Time        `json:"finished_at"`
+}
+
+// MarshalJSON renders the task the way the master does.
+func (t Task) MarshalJSON() ([]byte, error) {
+	type task Task
+	body := struct {
+		task
+		Payload interface{} `json:"payload,omitempty"`
+		Result  interface{} `json:"result,omitempty"`
+	}{task: task(t)}
+
+	if t.PayloadJSON != nil {
+		body.Payload = t.PayloadJSON
+	} else if t.Payload != "" {
+		body.Payload = t.Payload
+	}
+	if t.ResultJSON != nil {
+		body.Result = t.ResultJSON
+	} else if t.Result != "" {
+		body.Result = t.Result
+	}
+	return json.Marshal(body)
+}
+
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type task Task
+	body := struct {
+		*task
+		Payload json.RawMessage `json:"payload"`
+		Result  json.RawMessage `json:"result"`
+	}{task: (*task)(t)}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+
+	// Fields missing from the response, or null, keep their values
+	if present(body.Payload) {
+		if err := splitData(body.Payload, &t.Payload, &t.PayloadJSON); err != nil {
+			return err
+		}
+	}
+	if present(body.Result) {
+		return splitData(body.Result, &t.Result, &t.ResultJSON)
+	}
+	return nil
+}
+
+func present(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// splitData separates text, which the master sends as a JSON string, from
+// JSON values.
+func splitData(raw json.RawMessage, text *string, value *json.RawMessage) error {
+	if raw[0] == '"' {
+		*value = nil
+		return json.Unmarshal(raw, text)
+	}
+	*text = ""
+	*value = raw
+	return nil
+}
+
+// ResultData returns the result as bytes, whichever way it was sent.
+func (t *Task) ResultData() []byte {
+	switch {
+	case t.ResultBase64 != nil:
+		return t.ResultBase64
+	case t.ResultJSON != nil:
+		return t.ResultJSON
+	}
+	return []byte(t.Result)
+}
+
+// DecodeResult decodes a JSON result into v.
+func (t *Task) DecodeResult(v interface{}) error {
+	if t.ResultJSON == nil {
+		return fmt.Errorf("task %s has no JSON result", t.ID)
+	}
+	return json.Unmarshal(t.ResultJSON, v)
+}
+
+// Worker is a worker in the master's pool.
+type Worker struct {
+	ID        string            `json:"id"`
+	URL       string            `json:"url"`
+	Cordoned  bool              `json:"cordoned"`
+	TaskTypes []string          `json:"task_types"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// Blob is a blob stored on the master. Submitting a task with Ref as its
+// payload has the worker fetch the blob instead of receiving the data.
+type Blob struct {
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Ref       string    `json:"ref"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskType is a task type declared on the master, with the JSON Schemas its
+// payloads and results must match.
+type TaskType struct {
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	PayloadSchema json.RawMessage `json:"payload_schema"`
+	ResultSchema  json.RawMessage `json:"result_schema"`
+	Deterministic bool            `json:"deterministic"`
+	Timeout       string          `json:"timeout"`
+	MaxTimeout    string          `json:"max_timeout"`
+}
+
+// WorkerStatus is the live status a worker reports.
+type WorkerStatus struct {
+	WorkerID    string            `json:"worker_id"`
+	Status      string            `json:"status"`
+	ActiveTasks int32             `json:"active_tasks"`
+	TaskTypes   []string          `json:"task_types"`
+	Labels      map[string]string `json:"labels"`
+}
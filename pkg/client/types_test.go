@@ -0,0 +1,35 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTaskUnmarshalData(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		result     string
+		resultJSON string
+	}{
+		{"text", `{"result": "4"}`, "4", ""},
+		{"json", `{"result": {"sum": 4}}`, "", `{"sum": 4}`},
+		{"missing", `{}`, "", ""},
+		{"null", `{"result": null}`, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var task Task
+			if err := json.Unmarshal([]byte(tt.body), &task); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if task.Result != tt.result || string(task.ResultJSON) != tt.resultJSON {
+				t.Errorf("Result = %q, ResultJSON = %q, want %q and %q", task.Result, task.ResultJSON, tt.result, tt.resultJSON)
+			}
+			if tt.resultJSON == "" && task.ResultJSON != nil {
+				t.Errorf("ResultJSON = %q, want nil", task.ResultJSON)
+			}
+		})
+	}
+}
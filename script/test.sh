@@ -42,16 +42,16 @@ curl -s http://localhost:8080/health | jq .
 
 # Test submitting a task
 echo "Submitting a task..."
-curl -s -X POST http://localhost:8080/tasks \
+curl -s -X POST http://localhost:8080/v1/tasks \
   -H "Content-Type: application/json" \
   -d '{"task_type": "compute", "payload": "2+2"}' | jq .
 
 # Test getting all worker statuses
 echo "Getting all worker statuses..."
-curl -s http://localhost:8080/status | jq .
+curl -s http://localhost:8080/v1/status | jq .
 
 # Test getting specific worker status
 echo "Getting specific worker status..."
-curl -s http://localhost:8080/status/worker-1 | jq .
+curl -s http://localhost:8080/v1/status/worker-1 | jq .
 
 echo "Test complete!"